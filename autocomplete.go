@@ -0,0 +1,145 @@
+package q3m
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Completion is a completion of a partially typed address.
+type Completion struct {
+	Text string `json:"text"` // the input with its last word completed
+
+	// Coordinate is the centre of the cell of a completed address, and
+	// Distance its distance in metres from the focus of AutocompleteNear.
+	Coordinate *Coordinate `json:"coordinate,omitempty"`
+	Distance   float64     `json:"distance,omitempty"`
+}
+
+// Autocomplete completes the last word of partial with DefaultCodec (see
+// Codec.Autocomplete).
+func Autocomplete(partial string, limit int) ([]string, error) {
+	return defaultCodec().Autocomplete(partial, limit)
+}
+
+// AutocompleteNear is Autocomplete ranking completed addresses by distance
+// to focus, with DefaultCodec (see Codec.AutocompleteNear).
+func AutocompleteNear(partial string, focus Coordinate, limit int) ([]Completion, error) {
+	return defaultCodec().AutocompleteNear(partial, focus, limit)
+}
+
+// Autocomplete returns up to limit completions of the last, partially typed
+// word of partial, in alphabetical order: each is partial with that word
+// completed, e.g. "province.shoo" gives "province.shootons". The words
+// before it must be complete. Once the last word completes the address (the
+// third word, or a sub-cell fourth word), only words giving a valid address
+// are proposed. A partial with a resolution marker has no completion. A
+// limit of zero or less returns every completion.
+//
+// Errors are *AddressError values: ErrUnknownWord for a complete word
+// missing from the dictionary, ErrInvalidFormat for too many words or an
+// unknown region, ErrNamespace for a private partial given to a public codec
+// or the reverse.
+func (c *Codec) Autocomplete(partial string, limit int) ([]string, error) {
+	p, err := c.partial(partial)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	var out []string
+	for _, w := range p.cands {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		text := p.head + w
+		if p.last {
+			if _, _, err := p.codec.parse(text); err != nil {
+				continue
+			}
+		}
+		out = append(out, text)
+	}
+	return out, nil
+}
+
+// AutocompleteNear is Autocomplete where completions forming a whole
+// address, such as third words once two words are typed, are ranked by the
+// distance of their cell to focus, closest first, and carry their
+// coordinate. Other completions are those of Autocomplete.
+func (c *Codec) AutocompleteNear(partial string, focus Coordinate, limit int) ([]Completion, error) {
+	p, err := c.partial(partial)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if !p.last {
+		texts, err := c.Autocomplete(partial, limit)
+		out := make([]Completion, len(texts))
+		for i, t := range texts {
+			out[i] = Completion{Text: t}
+		}
+		return out, err
+	}
+
+	var out []Completion
+	for _, w := range p.cands {
+		text := p.head + w
+		coord, _, err := p.codec.DecodePrecise(text)
+		if err != nil {
+			continue
+		}
+		d, _ := Geodesic(focus, coord)
+		out = append(out, Completion{Text: text, Coordinate: &coord, Distance: d})
+	}
+	slices.SortStableFunc(out, func(a, b Completion) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// partialAddress is a partially typed address split for completion.
+type partialAddress struct {
+	codec *Codec   // codec of the region of the address
+	head  string   // the input up to its last word
+	cands []string // dictionary words completing the last word
+	last  bool     // whether the last word completes the address
+}
+
+// partial splits the partially typed address s, or returns nil if there is
+// nothing to complete.
+func (c *Codec) partial(s string) (*partialAddress, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.Contains(s, ResolutionMarker) {
+		return nil, nil
+	}
+	rc, err := c.codecFor(s)
+	if err != nil {
+		return nil, err
+	}
+	body, private := strings.CutPrefix(s, PrivateMarker)
+	if private != c.private {
+		return nil, &AddressError{Address: s, Reason: ReasonNamespace}
+	}
+	if _, words, ok := strings.Cut(body, RegionMarker); ok {
+		body = words
+	}
+
+	parts := strings.Split(body, ".")
+	n := c.Words(Res1m)
+	if len(parts) > n+1 || len(parts) == n+1 && !c.precise(Precision1cm) {
+		return nil, &AddressError{Address: s, Reason: ReasonInvalidFormat}
+	}
+	typed := parts[len(parts)-1]
+	for i, w := range parts[:len(parts)-1] {
+		if _, ok := c.dict.Index(w); !ok {
+			return nil, &AddressError{Address: s, Position: i + 1, Token: w, Reason: ReasonUnknownWord}
+		}
+	}
+	return &partialAddress{
+		codec: rc,
+		head:  s[:len(s)-len(typed)],
+		cands: c.dict.Complete(typed, 0),
+		last:  len(parts) >= n,
+	}, nil
+}
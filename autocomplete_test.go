@@ -0,0 +1,101 @@
+package q3m
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDictionaryComplete(t *testing.T) {
+	d := DefaultDictionary()
+	all := d.Complete("Pro", 0)
+	if len(all) == 0 || !slices.IsSorted(all) || !slices.Contains(all, "province") {
+		t.Fatalf("Complete(Pro) = %q", all)
+	}
+	for _, w := range all {
+		if !strings.HasPrefix(w, "pro") {
+			t.Errorf("Complete(Pro) yields %q", w)
+		}
+	}
+	if got := d.Complete("pro", 3); !slices.Equal(got, all[:3]) {
+		t.Errorf("Complete(pro, 3) = %q, want %q", got, all[:3])
+	}
+	if got := d.Complete("", 0); len(got) != DictSize {
+		t.Errorf("Complete(\"\") = %d words", len(got))
+	}
+	if got := d.Complete("zzzz", 0); len(got) != 0 {
+		t.Errorf("Complete(zzzz) = %q", got)
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	tests := []struct {
+		partial, want string
+	}{
+		{"pro", "province"},
+		{"Province.SHOO", "province.shootons"},
+		{"province.shootons.retir", "province.shootons.retirons"},
+		{"province.shootons.retirons.", "province.shootons.retirons.abaissa"},
+		{"re:abjurer.ramassis.ross", "re:abjurer.ramassis.rossant"},
+	}
+	for _, tt := range tests {
+		got, err := Autocomplete(tt.partial, 0)
+		if err != nil || !slices.Contains(got, tt.want) {
+			t.Errorf("Autocomplete(%q) = %q, %v, want %q among them", tt.partial, got, err, tt.want)
+		}
+	}
+
+	got, _ := Autocomplete("province.shootons.", 20)
+	if len(got) != 20 || !slices.IsSorted(got) {
+		t.Errorf("Autocomplete(province.shootons., 20) = %q", got)
+	}
+	for _, a := range got {
+		if _, err := Decode(a); err != nil {
+			t.Errorf("completion %q: %v", a, err)
+		}
+	}
+
+	if got, err := Autocomplete("province.shootons@100m", 0); got != nil || err != nil {
+		t.Errorf("Autocomplete(resolution) = %q, %v", got, err)
+	}
+	errTests := []struct {
+		partial string
+		want    error
+	}{
+		{"provinxe.sh", ErrUnknownWord},
+		{"province.shootons.retirons.abaissa.a", ErrInvalidFormat},
+		{"xx:pro", ErrInvalidFormat},
+		{"~pro", ErrNamespace},
+	}
+	for _, tt := range errTests {
+		if _, err := Autocomplete(tt.partial, 0); !errors.Is(err, tt.want) {
+			t.Errorf("Autocomplete(%q) error = %v, want %v", tt.partial, err, tt.want)
+		}
+	}
+}
+
+func TestAutocompleteNear(t *testing.T) {
+	got, err := AutocompleteNear("province.shootons.", eiffel, 5)
+	if err != nil || len(got) != 5 {
+		t.Fatalf("AutocompleteNear = %v, %v", got, err)
+	}
+	if got[0].Text != "province.shootons.retirons" || got[0].Distance > 1 || got[0].Coordinate == nil {
+		t.Errorf("closest completion = %+v", got[0])
+	}
+	if !slices.IsSortedFunc(got, func(a, b Completion) int { return cmp.Compare(a.Distance, b.Distance) }) {
+		t.Errorf("completions not ranked by distance: %+v", got)
+	}
+
+	words, err := AutocompleteNear("province.sh", eiffel, 3)
+	if err != nil || len(words) != 3 || words[0].Coordinate != nil || !strings.HasPrefix(words[0].Text, "province.sh") {
+		t.Errorf("AutocompleteNear(second word) = %+v, %v", words, err)
+	}
+}
+
+func BenchmarkAutocompleteNear(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		AutocompleteNear("province.shootons.r", eiffel, 10)
+	}
+}
@@ -0,0 +1,106 @@
+package q3m
+
+// bkTree is a Burkhard-Keller tree over the dictionary, indexed by
+// Levenshtein distance. It answers "all words within d edits of q" while
+// visiting only a small fraction of the dictionary.
+type bkTree struct {
+	nodes []bkNode
+}
+
+type bkNode struct {
+	word     string
+	children []bkEdge
+}
+
+type bkEdge struct {
+	dist int
+	node int32
+}
+
+// newBKTree builds a tree containing words, inserted in order.
+func newBKTree(words []string) *bkTree {
+	t := &bkTree{nodes: make([]bkNode, 0, len(words))}
+	for _, w := range words {
+		t.insert(w)
+	}
+	return t
+}
+
+func (t *bkTree) insert(word string) {
+	if len(t.nodes) == 0 {
+		t.nodes = append(t.nodes, bkNode{word: word})
+		return
+	}
+	cur := 0
+	for {
+		d := levenshtein(word, t.nodes[cur].word)
+		if d == 0 {
+			return
+		}
+		next := -1
+		for _, e := range t.nodes[cur].children {
+			if e.dist == d {
+				next = int(e.node)
+				break
+			}
+		}
+		if next < 0 {
+			t.nodes = append(t.nodes, bkNode{word: word})
+			t.nodes[cur].children = append(t.nodes[cur].children, bkEdge{dist: d, node: int32(len(t.nodes) - 1)})
+			return
+		}
+		cur = next
+	}
+}
+
+// search calls fn for every word within maxDist edits of query.
+func (t *bkTree) search(query string, maxDist int, fn func(word string, dist int)) {
+	if len(t.nodes) == 0 {
+		return
+	}
+	stack := []int32{0}
+	for len(stack) > 0 {
+		n := &t.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+
+		d := levenshtein(query, n.word)
+		if d <= maxDist {
+			fn(n.word, d)
+		}
+		for _, e := range n.children {
+			if e.dist >= d-maxDist && e.dist <= d+maxDist {
+				stack = append(stack, e.node)
+			}
+		}
+	}
+}
+
+// levenshtein returns the edit distance between a and b, counted in bytes
+// (dictionary words are ASCII).
+func levenshtein(a, b string) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	var buf [32]int
+	prev := buf[:0]
+	if len(b)+1 > len(buf) {
+		prev = make([]int, 0, len(b)+1)
+	}
+	for j := 0; j <= len(b); j++ {
+		prev = append(prev, j)
+	}
+	for i := 1; i <= len(a); i++ {
+		diag := prev[0]
+		prev[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur := min(prev[j]+1, prev[j-1]+1, diag+cost)
+			diag = prev[j]
+			prev[j] = cur
+		}
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,61 @@
+package q3m
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"chat", "chat", 0},
+		{"chat", "chats", 1},
+		{"chat", "chou", 2},
+		{"kitten", "sitting", 3},
+		{"province", "provinec", 2},
+	}
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := levenshtein(c.b, c.a); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.b, c.a, got, c.want)
+		}
+	}
+}
+
+func TestBKTreeMatchesBruteForce(t *testing.T) {
+	loadWords()
+	tree := dictTree()
+	for _, q := range []string{"provinse", "chateau", "abandn", "zzzz", "retirons"} {
+		var got []string
+		tree.search(q, 2, func(w string, d int) {
+			if d != levenshtein(q, w) {
+				t.Errorf("search(%q) reported %q at %d, want %d", q, w, d, levenshtein(q, w))
+			}
+			got = append(got, w)
+		})
+		var want []string
+		for _, w := range wordsList {
+			if levenshtein(q, w) <= 2 {
+				want = append(want, w)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("search(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func BenchmarkBKTreeSearch(b *testing.B) {
+	tree := dictTree()
+	for i := 0; i < b.N; i++ {
+		tree.search("provinse", 2, func(string, int) {})
+	}
+}
@@ -0,0 +1,133 @@
+package q3m
+
+// Cell is a cell of the Lambert93 grid at some resolution, identified by
+// its linear index row*width + column in the grid of that resolution. The
+// zero value is the south-west 1m cell. Cells of an overseas region lie in
+// the UTM grid of that region instead (see Region).
+type Cell struct {
+	idx uint64
+	res Resolution
+	reg uint8 // region number, 0 for metropolitan France
+}
+
+// codec returns the codec of the grid of c.
+func (c Cell) codec() *Codec {
+	if c.reg == 0 {
+		return defaultCodec()
+	}
+	return defaultCodec().regions[c.reg-1]
+}
+
+// grid returns the grid of c.
+func (c Cell) grid() grid {
+	if c.reg == 0 {
+		return defaultGrids[c.res]
+	}
+	return c.codec().levels[c.res].grid
+}
+
+// Bounds is an axis-aligned rectangle in Lambert93 metres.
+type Bounds struct {
+	EMin, NMin, EMax, NMax float64
+}
+
+// CellFromIndex returns the cell with linear index idx.
+// Returns false if idx >= TotalCells.
+func CellFromIndex(idx uint64) (Cell, bool) {
+	if idx >= TotalCells {
+		return Cell{}, false
+	}
+	return Cell{idx: idx}, true
+}
+
+// CellAt returns the 1m cell containing the Lambert93 point (E, N).
+// Returns false if the point is outside the grid.
+func CellAt(E, N float64) (Cell, bool) {
+	idx, ok := CellIndex(E, N)
+	return Cell{idx: idx}, ok
+}
+
+// CellOf returns the 1m cell containing the WGS84 point (lat, lon), in the
+// grid of its overseas region if any. The error is an *OutOfGridError if
+// the point is outside the grid.
+func CellOf(lat, lon float64) (Cell, error) {
+	if rc := defaultCodec().regionAt(lat, lon); rc != nil {
+		e, n := rc.proj.forward(lat, lon)
+		idx, ok := rc.levels[Res1m].grid.cellIndex(e, n)
+		if !ok {
+			return Cell{}, &OutOfGridError{Lat: lat, Lon: lon, E: e, N: n}
+		}
+		return Cell{idx: idx, reg: rc.reg}, nil
+	}
+	e, n := ToLambert93(lat, lon)
+	c, ok := CellAt(e, n)
+	if !ok {
+		return Cell{}, &OutOfGridError{Lat: lat, Lon: lon, E: e, N: n}
+	}
+	return c, nil
+}
+
+// Cell returns the grid cell designated by a, at the resolution of a.
+// Errors are the same as Decode.
+func (a Address) Cell() (Cell, error) {
+	s := a.String()
+	codec, err := defaultCodec().codecFor(s)
+	if err != nil {
+		return Cell{}, err
+	}
+	_, c, err := codec.parse(s)
+	return c, err
+}
+
+// Index returns the linear index of c in the grid of its resolution.
+func (c Cell) Index() uint64 {
+	return c.idx
+}
+
+// Row returns the row of c, counted from the southern edge of the grid.
+func (c Cell) Row() uint64 {
+	return c.idx / c.grid().width
+}
+
+// Col returns the column of c, counted from the western edge of the grid.
+func (c Cell) Col() uint64 {
+	return c.idx % c.grid().width
+}
+
+// Bounds returns the Lambert93 square covered by c, in UTM metres for a
+// cell of an overseas region.
+func (c Cell) Bounds() Bounds {
+	g := c.grid()
+	size := float64(g.size)
+	e := g.bounds.EMin + float64(c.Col())*size
+	n := g.bounds.NMin + float64(c.Row())*size
+	return Bounds{EMin: e, NMin: n, EMax: e + size, NMax: n + size}
+}
+
+// Corners returns the WGS84 coordinates of the corners of c, in the order
+// south-west, south-east, north-east, north-west. The Lambert93 square is
+// slightly skewed once projected back to WGS84.
+func (c Cell) Corners() [4]Coordinate {
+	b := c.Bounds()
+	pts := [4][2]float64{
+		{b.EMin, b.NMin},
+		{b.EMax, b.NMin},
+		{b.EMax, b.NMax},
+		{b.EMin, b.NMax},
+	}
+	var out [4]Coordinate
+	for i, p := range pts {
+		out[i].Lat, out[i].Lon = c.codec().proj.inverse(p[0], p[1])
+	}
+	return out
+}
+
+// Center returns the WGS84 coordinates of the centre of c.
+func (c Cell) Center() Coordinate {
+	return c.codec().center(c)
+}
+
+// Address returns the q3m address of c.
+func (c Cell) Address() Address {
+	return c.codec().address(c.idx, c.res)
+}
@@ -0,0 +1,96 @@
+package q3m
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCellRowColBounds(t *testing.T) {
+	c, ok := CellAt(652469.7, 6862035.2)
+	if !ok {
+		t.Fatal("CellAt returned false")
+	}
+	if c.Col() != 552469 || c.Row() != 812035 {
+		t.Errorf("Col, Row = %d, %d; want 552469, 812035", c.Col(), c.Row())
+	}
+	if c.Index() != c.Row()*GridWidth+c.Col() {
+		t.Errorf("Index = %d, inconsistent with row/col", c.Index())
+	}
+	want := Bounds{EMin: 652469, NMin: 6862035, EMax: 652470, NMax: 6862036}
+	if got := c.Bounds(); got != want {
+		t.Errorf("Bounds = %+v, want %+v", got, want)
+	}
+}
+
+func TestCellFromIndex(t *testing.T) {
+	if _, ok := CellFromIndex(TotalCells); ok {
+		t.Error("CellFromIndex(TotalCells) should fail")
+	}
+	c, ok := CellFromIndex(TotalCells - 1)
+	if !ok || c.Col() != GridWidth-1 || c.Row() != GridHeight-1 {
+		t.Errorf("CellFromIndex(TotalCells-1) = %+v, %v", c, ok)
+	}
+}
+
+func TestCellAddressRoundTrip(t *testing.T) {
+	c, err := CellOf(48.8584, 2.2945)
+	if err != nil {
+		t.Fatalf("CellOf: %v", err)
+	}
+	addr := c.Address()
+	want, _ := Encode(48.8584, 2.2945)
+	if addr != want {
+		t.Errorf("Cell.Address = %s, want %s", addr, want)
+	}
+	back, err := addr.Cell()
+	if err != nil || back != c {
+		t.Errorf("Address.Cell = %+v, %v; want %+v", back, err, c)
+	}
+}
+
+func TestCellCenterMatchesDecode(t *testing.T) {
+	addr, _ := Encode(43.2951, 5.3743)
+	coord, _ := Decode(addr.String())
+	c, _ := addr.Cell()
+	if got := c.Center(); got != coord {
+		t.Errorf("Cell.Center = %+v, want %+v", got, coord)
+	}
+}
+
+func TestCellCorners(t *testing.T) {
+	c, _ := CellOf(48.8584, 2.2945)
+	corners := c.Corners()
+	b := c.Bounds()
+	for i, p := range [4][2]float64{{b.EMin, b.NMin}, {b.EMax, b.NMin}, {b.EMax, b.NMax}, {b.EMin, b.NMax}} {
+		e, n := ToLambert93(corners[i].Lat, corners[i].Lon)
+		if math.Abs(e-p[0]) > 1e-4 || math.Abs(n-p[1]) > 1e-4 {
+			t.Errorf("corner %d projects to (%f, %f), want (%f, %f)", i, e, n, p[0], p[1])
+		}
+	}
+	// The centre lies inside the corners' lat/lon envelope.
+	ctr := c.Center()
+	if ctr.Lat <= corners[0].Lat || ctr.Lat >= corners[2].Lat ||
+		ctr.Lon <= corners[0].Lon || ctr.Lon >= corners[2].Lon {
+		t.Errorf("centre %+v outside corners %+v", ctr, corners)
+	}
+	// Away from the central meridian the footprint is not a lat/lon square:
+	// the western edge is not a meridian.
+	if corners[0].Lon == corners[3].Lon {
+		t.Error("expected skewed corners away from the central meridian")
+	}
+}
+
+func TestCellOfOutOfGrid(t *testing.T) {
+	_, err := CellOf(0, 0)
+	if !errors.Is(err, ErrOutOfGrid) {
+		t.Errorf("CellOf(0, 0) error = %v, want ErrOutOfGrid", err)
+	}
+}
+
+func TestAddressCellInvalid(t *testing.T) {
+	_, err := Address{W1: "xyzzy", W2: "hello", W3: "world"}.Cell()
+	if !errors.Is(err, ErrUnknownWord) {
+		t.Errorf("Address.Cell error = %v, want ErrUnknownWord", err)
+	}
+}
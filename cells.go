@@ -0,0 +1,178 @@
+package q3m
+
+import (
+	"cmp"
+	"iter"
+	"math"
+	"slices"
+)
+
+// polygonStep is the largest step, in degrees, between the vertices of a
+// WGS84 polygon once densified: its edges, straight in latitude and
+// longitude, are curved in Lambert93.
+const polygonStep = 0.001
+
+// CellsIn returns the 1m cells of the metropolitan grid intersecting the
+// Lambert93 rectangle b, row by row from the south-west corner.
+func CellsIn(b Bounds) iter.Seq[Cell] {
+	return CellsInLambertPolygon([][2]float64{
+		{b.EMin, b.NMin}, {b.EMax, b.NMin}, {b.EMax, b.NMax}, {b.EMin, b.NMax},
+	})
+}
+
+// CellsInBBox returns the 1m cells of the metropolitan grid intersecting
+// the WGS84 box between the given latitudes and longitudes, row by row from
+// the south-west corner.
+func CellsInBBox(south, west, north, east float64) iter.Seq[Cell] {
+	return CellsInPolygon([]Coordinate{
+		{Lat: south, Lon: west}, {Lat: south, Lon: east}, {Lat: north, Lon: east}, {Lat: north, Lon: west},
+	})
+}
+
+// CellsInPolygon returns the 1m cells of the metropolitan grid intersecting
+// the WGS84 polygon made of rings, row by row from the south-west corner.
+// Rings need not be closed. Inside and outside follow the even-odd rule, so
+// that the holes of a polygon, and the parts of a multipolygon, can be
+// given together.
+func CellsInPolygon(rings ...[]Coordinate) iter.Seq[Cell] {
+	return CellsInLambertPolygon(projectRings(rings)...)
+}
+
+// CellsInLambertPolygon is CellsInPolygon for rings of Lambert93 (E, N)
+// points.
+func CellsInLambertPolygon(rings ...[][2]float64) iter.Seq[Cell] {
+	edges, b := polygonEdges(rings)
+	return func(yield func(Cell) bool) {
+		if len(edges) == 0 {
+			return
+		}
+		g := defaultGrid
+		rows, ok := cellSpan(b.NMin-g.bounds.NMin, b.NMax-g.bounds.NMin, g.height)
+		if !ok {
+			return
+		}
+		var spans, cols [][2]float64
+		var xs []float64
+		for row := rows[0]; row <= rows[1]; row++ {
+			// The polygon meets the strip of the row where it crosses its
+			// lower or upper edge, or where its outline runs inside it.
+			y0 := g.bounds.NMin + row
+			spans = scanSpans(spans[:0], &xs, edges, y0)
+			spans = scanSpans(spans, &xs, edges, y0+1)
+			for _, e := range edges {
+				if lo, hi := e.clip(y0, y0+1); lo <= hi {
+					spans = append(spans, [2]float64{lo, hi})
+				}
+			}
+
+			cols = cols[:0]
+			for _, s := range spans {
+				if c, ok := cellSpan(s[0]-g.bounds.EMin, s[1]-g.bounds.EMin, g.width); ok {
+					cols = append(cols, c)
+				}
+			}
+			slices.SortFunc(cols, func(a, b [2]float64) int { return cmp.Compare(a[0], b[0]) })
+			next := 0.0 // first column not yet yielded
+			for _, c := range cols {
+				for col := max(c[0], next); col <= c[1]; col++ {
+					if !yield(Cell{idx: uint64(row)*g.width + uint64(col)}) {
+						return
+					}
+				}
+				next = max(next, c[1]+1)
+			}
+		}
+	}
+}
+
+// projectRings projects WGS84 rings to Lambert93, densified so that their
+// edges stay straight in latitude and longitude.
+func projectRings(rings [][]Coordinate) [][][2]float64 {
+	projected := make([][][2]float64, 0, len(rings))
+	for _, ring := range rings {
+		var pts [][2]float64
+		for i, p := range ring {
+			q := ring[(i+1)%len(ring)]
+			n := max(1, int(math.Ceil(max(math.Abs(q.Lat-p.Lat), math.Abs(q.Lon-p.Lon))/polygonStep)))
+			for j := range n {
+				t := float64(j) / float64(n)
+				x, y := ToLambert93(p.Lat+t*(q.Lat-p.Lat), p.Lon+t*(q.Lon-p.Lon))
+				pts = append(pts, [2]float64{x, y})
+			}
+		}
+		projected = append(projected, pts)
+	}
+	return projected
+}
+
+// polygonEdges returns the edges of rings, closed if need be, and their
+// bounds.
+func polygonEdges(rings [][][2]float64) ([]polygonEdge, Bounds) {
+	var edges []polygonEdge
+	b := Bounds{EMin: math.Inf(1), NMin: math.Inf(1), EMax: math.Inf(-1), NMax: math.Inf(-1)}
+	for _, ring := range rings {
+		for i, p := range ring {
+			q := ring[(i+1)%len(ring)]
+			if p != q {
+				edges = append(edges, polygonEdge{p, q})
+			}
+			b.EMin, b.EMax = min(b.EMin, p[0]), max(b.EMax, p[0])
+			b.NMin, b.NMax = min(b.NMin, p[1]), max(b.NMax, p[1])
+		}
+	}
+	return edges, b
+}
+
+// polygonEdge is an edge of a polygon, in Lambert93 metres.
+type polygonEdge struct {
+	p, q [2]float64
+}
+
+// clip returns the range of E of the part of e between the northings y0
+// and y1, empty (lo > hi) if there is none.
+func (e polygonEdge) clip(y0, y1 float64) (lo, hi float64) {
+	p, q := e.p, e.q
+	if p[1] > q[1] {
+		p, q = q, p
+	}
+	if q[1] < y0 || p[1] > y1 {
+		return 1, 0
+	}
+	if p[1] == q[1] {
+		return min(p[0], q[0]), max(p[0], q[0])
+	}
+	xa := e.x(max(p[1], y0))
+	xb := e.x(min(q[1], y1))
+	return min(xa, xb), max(xa, xb)
+}
+
+// x returns the E of the point of the line of e at northing y.
+func (e polygonEdge) x(y float64) float64 {
+	return e.p[0] + (y-e.p[1])*(e.q[0]-e.p[0])/(e.q[1]-e.p[1])
+}
+
+// scanSpans appends to spans the ranges of E inside the polygon of edges on
+// the line of northing y, by the even-odd rule. xs is scratch space.
+func scanSpans(spans [][2]float64, xs *[]float64, edges []polygonEdge, y float64) [][2]float64 {
+	*xs = (*xs)[:0]
+	for _, e := range edges {
+		if (e.p[1] > y) != (e.q[1] > y) {
+			*xs = append(*xs, e.x(y))
+		}
+	}
+	slices.Sort(*xs)
+	for i := 0; i+1 < len(*xs); i += 2 {
+		spans = append(spans, [2]float64{(*xs)[i], (*xs)[i+1]})
+	}
+	return spans
+}
+
+// cellSpan returns the first and last of n unit cells along an axis
+// meeting the range [lo, hi], in cell units from the edge of the grid, or
+// false if there are none. A cell merely touching the range on its edge
+// does not meet it.
+func cellSpan(lo, hi float64, n uint64) ([2]float64, bool) {
+	first := max(math.Floor(lo), 0)
+	last := min(math.Ceil(hi)-1, float64(n)-1)
+	return [2]float64{first, last}, first <= last
+}
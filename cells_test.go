@@ -0,0 +1,128 @@
+package q3m
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// collect returns the indexes of the cells of seq, failing if they are not
+// in increasing order.
+func collect(t *testing.T, cells func(func(Cell) bool)) []uint64 {
+	t.Helper()
+	var out []uint64
+	for c := range cells {
+		if n := len(out); n > 0 && c.idx <= out[n-1] {
+			t.Fatalf("cell %d after %d", c.idx, out[n-1])
+		}
+		out = append(out, c.idx)
+	}
+	return out
+}
+
+func TestCellsIn(t *testing.T) {
+	b := Bounds{EMin: 648200.5, NMin: 6862200.5, EMax: 648210, NMax: 6862205}
+	got := collect(t, CellsIn(b))
+	if len(got) != 10*5 {
+		t.Fatalf("CellsIn(%v) = %d cells, want 50", b, len(got))
+	}
+	if first, _ := CellIndex(b.EMin, b.NMin); got[0] != first {
+		t.Errorf("first cell %d, want %d", got[0], first)
+	}
+	if last, _ := CellIndex(b.EMax-0.5, b.NMax-0.5); got[len(got)-1] != last {
+		t.Errorf("last cell %d, want %d", got[len(got)-1], last)
+	}
+
+	if n := len(collect(t, CellsIn(Bounds{EMin: 0, NMin: 0, EMax: 10, NMax: 10}))); n != 0 {
+		t.Errorf("CellsIn outside the grid = %d cells", n)
+	}
+	corner := Bounds{EMin: EMin - 5, NMin: NMin - 5, EMax: EMin + 2, NMax: NMin + 3}
+	if got := collect(t, CellsIn(corner)); !slices.Equal(got, []uint64{0, 1, GridWidth, GridWidth + 1, 2 * GridWidth, 2*GridWidth + 1}) {
+		t.Errorf("CellsIn(grid corner) = %v", got)
+	}
+}
+
+func TestCellsInLambertPolygon(t *testing.T) {
+	const e0, n0 = 648000, 6862000
+	tri := [][2]float64{{e0 + 0.3, n0 + 0.7}, {e0 + 25.9, n0 + 3.2}, {e0 + 8.45, n0 + 19.6}}
+
+	var want []uint64
+	for y := range 20 {
+		for x := range 26 {
+			x, y := float64(x), float64(y)
+			sq := [][2]float64{{e0 + x, n0 + y}, {e0 + x + 1, n0 + y}, {e0 + x + 1, n0 + y + 1}, {e0 + x, n0 + y + 1}}
+			if convexIntersect(tri, sq) {
+				idx, _ := CellIndex(e0+x, n0+y)
+				want = append(want, idx)
+			}
+		}
+	}
+	if got := collect(t, CellsInLambertPolygon(tri)); !slices.Equal(got, want) {
+		t.Errorf("CellsInLambertPolygon(triangle) = %d cells, want %d", len(got), len(want))
+	}
+}
+
+func TestCellsInPolygonHole(t *testing.T) {
+	const e0, n0 = 648000, 6862000
+	outer := [][2]float64{{e0, n0}, {e0 + 20, n0}, {e0 + 20, n0 + 20}, {e0, n0 + 20}}
+	hole := [][2]float64{{e0 + 5.5, n0 + 5.5}, {e0 + 14.5, n0 + 5.5}, {e0 + 14.5, n0 + 14.5}, {e0 + 5.5, n0 + 14.5}}
+	got := collect(t, CellsInLambertPolygon(outer, hole))
+	// The 8 x 8 cells strictly inside the hole are left out.
+	if len(got) != 400-64 {
+		t.Errorf("polygon with a hole = %d cells, want 336", len(got))
+	}
+	inHole, _ := CellIndex(e0+10, n0+10)
+	if slices.Contains(got, inHole) {
+		t.Error("cell in the hole yielded")
+	}
+}
+
+func TestCellsInBBox(t *testing.T) {
+	const d = 0.0002 // some 15 to 22m
+	cells := collect(t, CellsInBBox(eiffel.Lat-d, eiffel.Lon-d, eiffel.Lat+d, eiffel.Lon+d))
+	c, _ := CellOf(eiffel.Lat, eiffel.Lon)
+	if !slices.Contains(cells, c.idx) {
+		t.Error("CellsInBBox misses the centre cell")
+	}
+	// 2d of latitude by 2d of longitude, in square metres.
+	area := 2 * d * 111_200 * 2 * d * 111_320 * math.Cos(eiffel.Lat*math.Pi/180)
+	if n := float64(len(cells)); n < area || n > area*1.3 {
+		t.Errorf("CellsInBBox = %v cells, want about %.0f", n, area)
+	}
+
+	n := 0
+	for range CellsInBBox(eiffel.Lat-d, eiffel.Lon-d, eiffel.Lat+d, eiffel.Lon+d) {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("break after %d cells", n)
+	}
+}
+
+// convexIntersect reports whether the convex polygons a and b meet, by the
+// separating axis theorem.
+func convexIntersect(a, b [][2]float64) bool {
+	for _, poly := range [][][2]float64{a, b} {
+		for i, p := range poly {
+			q := poly[(i+1)%len(poly)]
+			nx, ny := q[1]-p[1], p[0]-q[0]
+			minA, maxA := project(a, nx, ny)
+			minB, maxB := project(b, nx, ny)
+			if maxA < minB || maxB < minA {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func project(poly [][2]float64, nx, ny float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, p := range poly {
+		v := p[0]*nx + p[1]*ny
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return lo, hi
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+// maxAroundRadius bounds the size of the printed grid.
+const maxAroundRadius = 25
+
+var aroundRadius int
+
+var aroundCmd = &cobra.Command{
+	Use:         "around <lat> <lon>",
+	Short:       "Affiche les adresses des cellules voisines d'un point",
+	Args:        cobra.ExactArgs(2),
+	Annotations: map[string]string{formatsAnnotation: formatGeoJSON},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		lat, err := parseFloatArg("latitude", args[0])
+		if err != nil {
+			return err
+		}
+		lon, err := parseFloatArg("longitude", args[1])
+		if err != nil {
+			return err
+		}
+		if aroundRadius < 0 || aroundRadius > maxAroundRadius {
+			return &argError{name: "rayon", err: fmt.Errorf("%d hors de [0, %d]", aroundRadius, maxAroundRadius)}
+		}
+
+		addr, err := q3m.Encode(lat, lon)
+		if err != nil {
+			return err
+		}
+		rows, err := q3m.Window(addr, aroundRadius)
+		if err != nil {
+			return err
+		}
+
+		switch outputFormat {
+		case formatGeoJSON:
+			fc := q3m.NewFeatureCollection()
+			for i, row := range rows {
+				for j, a := range row {
+					c, err := a.Cell()
+					if err != nil {
+						continue // outside the grid
+					}
+					f := c.GeoJSON()
+					f.Properties["dx"] = j - aroundRadius
+					f.Properties["dy"] = aroundRadius - i
+					fc.Add(f)
+				}
+			}
+			writeJSON(fc)
+		case formatJSON:
+			matrix := make([][]*string, len(rows))
+			for i, row := range rows {
+				matrix[i] = make([]*string, len(row))
+				for j, a := range row {
+					if a != (q3m.Address{}) {
+						s := a.String()
+						matrix[i][j] = &s
+					}
+				}
+			}
+			out := struct {
+				Address string      `json:"address"`
+				Lat     float64     `json:"lat"`
+				Lon     float64     `json:"lon"`
+				Radius  int         `json:"radius"`
+				Rows    [][]*string `json:"rows"`
+			}{
+				Address: addr.String(),
+				Lat:     lat,
+				Lon:     lon,
+				Radius:  aroundRadius,
+				Rows:    matrix,
+			}
+			writeJSON(out)
+		default:
+			printAroundTable(rows, aroundRadius)
+		}
+		return nil
+	},
+}
+
+// printAroundTable prints rows as aligned columns, the centre cell between
+// brackets and cells outside the grid as "-".
+func printAroundTable(rows [][]q3m.Address, k int) {
+	cells := make([][]string, len(rows))
+	width := 0
+	for i, row := range rows {
+		cells[i] = make([]string, len(row))
+		for j, a := range row {
+			s := "-"
+			if a != (q3m.Address{}) {
+				s = a.String()
+			}
+			if i == k && j == k {
+				s = "[" + s + "]"
+			}
+			cells[i][j] = s
+			width = max(width, len(s))
+		}
+	}
+	fmt.Println("N")
+	for _, row := range cells {
+		for j, s := range row {
+			if j < len(row)-1 {
+				s += strings.Repeat(" ", width-len(s)+2)
+			}
+			fmt.Print(s)
+		}
+		fmt.Println()
+	}
+	fmt.Println("S")
+}
+
+func init() {
+	aroundCmd.Flags().IntVar(&aroundRadius, "radius", 1, "nombre de cellules autour du point")
+	rootCmd.AddCommand(aroundCmd)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Liste les mots du dictionnaire qui se prononcent de même",
+	Long: "Regroupe les mots du dictionnaire par clé phonétique et liste les groupes\n" +
+		"d'au moins deux mots, les plus grands d'abord: ce sont les mots qu'un\n" +
+		"auditeur peut confondre quand une adresse est dictée.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dict := q3m.DefaultDictionary()
+		res := auditResult{Words: dict.Len(), Groups: dict.PhoneticGroups()}
+		for _, g := range res.Groups {
+			res.Shared += len(g.Words)
+		}
+
+		if jsonOutput {
+			writeJSON(res)
+			return nil
+		}
+		for _, g := range res.Groups {
+			fmt.Printf("%s: %s\n", g.Key, strings.Join(g.Words, ", "))
+		}
+		fmt.Printf("%d groupes, %d mots sur %d partagent une clé phonétique\n", len(res.Groups), res.Shared, res.Words)
+		return nil
+	},
+}
+
+// auditResult is the JSON shape of the dictionary audit.
+type auditResult struct {
+	Words  int                 `json:"words"`  // dictionary size
+	Shared int                 `json:"shared"` // words sharing a phonetic key
+	Groups []q3m.PhoneticGroup `json:"groups"`
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
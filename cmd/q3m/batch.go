@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+// batchSize is the number of rows processed in parallel before being
+// written out, in input order.
+const batchSize = 4096
+
+var (
+	batchLat       string
+	batchLon       string
+	batchAddress   string
+	batchDelimiter string
+	batchNoHeader  bool
+	batchWorkers   int
+	batchOutput    string
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Encode ou décode un fichier CSV/TSV",
+	Long: "Lit un fichier CSV/TSV (ou l'entrée standard) et ajoute à chaque ligne\n" +
+		"les colonnes calculées, plus une colonne error en cas d'échec.\n" +
+		"Les colonnes se désignent par leur nom ou par leur numéro (à partir de 1).",
+}
+
+var batchEncodeCmd = &cobra.Command{
+	Use:   "encode [fichier]",
+	Short: "Ajoute une colonne address à partir des colonnes lat et lon",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runBatch(args, []string{batchLat, batchLon}, []string{"address"}, encodeRow)
+	},
+}
+
+var batchDecodeCmd = &cobra.Command{
+	Use:   "decode [fichier]",
+	Short: "Ajoute des colonnes lat et lon à partir de la colonne address",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runBatch(args, []string{batchAddress}, []string{"lat", "lon"}, decodeRow)
+	},
+}
+
+// rowFunc computes the appended columns from the selected input fields.
+type rowFunc func(fields []string) ([]string, error)
+
+func encodeRow(fields []string) ([]string, error) {
+	lat, err := parseFloatArg("latitude", strings.TrimSpace(fields[0]))
+	if err != nil {
+		return nil, err
+	}
+	lon, err := parseFloatArg("longitude", strings.TrimSpace(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	addr, err := q3m.Encode(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return []string{addr.String()}, nil
+}
+
+func decodeRow(fields []string) ([]string, error) {
+	coord, err := q3m.Decode(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		strconv.FormatFloat(coord.Lat, 'f', 6, 64),
+		strconv.FormatFloat(coord.Lon, 'f', 6, 64),
+	}, nil
+}
+
+// runBatch streams the input CSV, applies fn to the columns named by
+// selectors and writes each row with the added columns and an error column.
+func runBatch(args, selectors, added []string, fn rowFunc) error {
+	in, name := io.Reader(os.Stdin), "-"
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in, name = f, args[0]
+	}
+
+	out := io.Writer(os.Stdout)
+	if batchOutput != "" && batchOutput != "-" {
+		f, err := os.Create(batchOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	comma, err := batchComma(name)
+	if err != nil {
+		return err
+	}
+	r := csv.NewReader(in)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	w := csv.NewWriter(out)
+	w.Comma = comma
+
+	var header []string
+	if !batchNoHeader {
+		header, err = r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.Write(append(append(header[:len(header):len(header)], added...), "error")); err != nil {
+			return err
+		}
+	}
+	cols := make([]int, len(selectors))
+	for i, s := range selectors {
+		if cols[i], err = columnIndex(header, s); err != nil {
+			return err
+		}
+	}
+
+	workers := batchWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	rows := make([][]string, 0, batchSize)
+	for {
+		rec, err := r.Read()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if rec != nil {
+			rows = append(rows, rec)
+		}
+		if len(rows) == batchSize || (err == io.EOF && len(rows) > 0) {
+			processRows(rows, cols, len(added), fn, workers)
+			if err := w.WriteAll(rows); err != nil {
+				return err
+			}
+			rows = rows[:0]
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// processRows appends the computed and error columns to every row, spreading
+// the work over workers goroutines. Rows keep their order.
+func processRows(rows [][]string, cols []int, nAdded int, fn rowFunc, workers int) {
+	var wg sync.WaitGroup
+	chunk := (len(rows) + workers - 1) / workers
+	for start := 0; start < len(rows); start += chunk {
+		part := rows[start:min(start+chunk, len(rows))]
+		wg.Go(func() {
+			for i, rec := range part {
+				part[i] = processRow(rec, cols, nAdded, fn)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func processRow(rec []string, cols []int, nAdded int, fn rowFunc) []string {
+	fields := make([]string, len(cols))
+	var err error
+	for i, c := range cols {
+		if c >= len(rec) {
+			err = fmt.Errorf("colonne %d absente", c+1)
+			break
+		}
+		fields[i] = rec[c]
+	}
+	var values []string
+	if err == nil {
+		values, err = fn(fields)
+	}
+	if err != nil {
+		values = make([]string, nAdded)
+		return append(append(rec, values...), err.Error())
+	}
+	return append(append(rec, values...), "")
+}
+
+// batchComma returns the field delimiter: --delimiter if set, else tab for
+// .tsv files and comma otherwise.
+func batchComma(name string) (rune, error) {
+	switch batchDelimiter {
+	case "":
+		if strings.HasSuffix(strings.ToLower(name), ".tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r := []rune(batchDelimiter)
+	if len(r) != 1 {
+		return 0, &argError{name: "délimiteur", err: fmt.Errorf("%q doit être un seul caractère", batchDelimiter)}
+	}
+	return r[0], nil
+}
+
+// columnIndex resolves a column given by name (looked up in header) or by
+// 1-based number.
+func columnIndex(header []string, sel string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), sel) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(sel)
+	if err != nil {
+		if header == nil {
+			err = errors.New("sans en-tête, désigner les colonnes par numéro")
+		} else {
+			err = fmt.Errorf("colonne %q introuvable", sel)
+		}
+		return 0, &argError{name: "colonne", err: err}
+	}
+	if n < 1 || (header != nil && n > len(header)) {
+		return 0, &argError{name: "colonne", err: fmt.Errorf("numéro %d hors limites", n)}
+	}
+	return n - 1, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{batchEncodeCmd, batchDecodeCmd} {
+		c.Flags().StringVar(&batchDelimiter, "delimiter", "", `séparateur de champs (défaut: tabulation pour .tsv, "," sinon)`)
+		c.Flags().BoolVar(&batchNoHeader, "no-header", false, "l'entrée n'a pas de ligne d'en-tête")
+		c.Flags().IntVar(&batchWorkers, "workers", 0, "nombre de lignes traitées en parallèle (défaut: nombre de CPU)")
+		c.Flags().StringVarP(&batchOutput, "output", "o", "", "fichier de sortie (défaut: sortie standard)")
+	}
+	batchEncodeCmd.Flags().StringVar(&batchLat, "lat", "lat", "colonne de latitude (nom ou numéro)")
+	batchEncodeCmd.Flags().StringVar(&batchLon, "lon", "lon", "colonne de longitude (nom ou numéro)")
+	batchDecodeCmd.Flags().StringVar(&batchAddress, "address", "address", "colonne d'adresse (nom ou numéro)")
+
+	batchCmd.AddCommand(batchEncodeCmd, batchDecodeCmd)
+	rootCmd.AddCommand(batchCmd)
+}
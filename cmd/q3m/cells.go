@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of cells besides text, json and geojson.
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+var (
+	cellsBBox    string
+	cellsLambert bool
+	cellsPolygon string
+	cellsMax     int
+)
+
+var cellsCmd = &cobra.Command{
+	Use:   "cells (--bbox ouest,sud,est,nord | --polygon fichier.geojson)",
+	Short: "Liste les adresses des cellules de 1m d'une zone",
+	Long: "Liste les adresses des cellules de 1m qui touchent un rectangle ou un\n" +
+		"polygone, ligne par ligne depuis le coin sud-ouest. Le nombre de cellules\n" +
+		"est vérifié avant toute sortie : au-delà de --max, la commande échoue.",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{formatsAnnotation: formatCSV + "," + formatNDJSON + "," + formatGeoJSON},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cells, err := cellsArea()
+		if err != nil {
+			return err
+		}
+		n := 0
+		for range cells {
+			if n++; n > cellsMax {
+				return &argError{name: "zone", err: fmt.Errorf("plus de %d cellules (--max)", cellsMax)}
+			}
+		}
+		return writeCells(os.Stdout, cells)
+	},
+}
+
+// cellsArea returns the cells of --bbox or --polygon.
+func cellsArea() (iter.Seq[q3m.Cell], error) {
+	switch {
+	case (cellsBBox == "") == (cellsPolygon == ""):
+		return nil, &argError{name: "zone", err: errors.New("--bbox ou --polygon requis, pas les deux")}
+	case cellsPolygon != "":
+		rings, err := readPolygon(cellsPolygon)
+		if err != nil {
+			return nil, err
+		}
+		return q3m.CellsInPolygon(rings...), nil
+	}
+	parts := strings.Split(cellsBBox, ",")
+	if len(parts) != 4 {
+		return nil, &argError{name: "bbox", err: fmt.Errorf("%q (ouest,sud,est,nord)", cellsBBox)}
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, &argError{name: "bbox", err: err}
+		}
+		v[i] = f
+	}
+	if v[0] > v[2] || v[1] > v[3] {
+		return nil, &argError{name: "bbox", err: fmt.Errorf("%q (ouest,sud,est,nord)", cellsBBox)}
+	}
+	if cellsLambert {
+		return q3m.CellsIn(q3m.Bounds{EMin: v[0], NMin: v[1], EMax: v[2], NMax: v[3]}), nil
+	}
+	return q3m.CellsInBBox(v[1], v[0], v[3], v[2]), nil
+}
+
+// readPolygon reads the rings of the Polygon and MultiPolygon geometries
+// of a GeoJSON file, "-" for the standard input.
+func readPolygon(path string) ([][]q3m.Coordinate, error) {
+	zones, err := readZones("polygone", path, "")
+	if err != nil {
+		return nil, err
+	}
+	var rings [][]q3m.Coordinate
+	for _, z := range zones {
+		rings = append(rings, z.Rings...)
+	}
+	return rings, nil
+}
+
+// readZones reads the zones of the GeoJSON file path, "-" for the standard
+// input, given by the flag name. A file without polygons is an error.
+func readZones(name, path, nameKey string) ([]q3m.Zone, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, &argError{name: name, err: err}
+		}
+		defer f.Close()
+		r = f
+	}
+	zones, err := q3m.ReadZones(r, nameKey)
+	if err != nil {
+		return nil, &argError{name: name, err: err}
+	}
+	if len(zones) == 0 {
+		return nil, &argError{name: name, err: errors.New("aucun Polygon ni MultiPolygon")}
+	}
+	return zones, nil
+}
+
+// writeCells streams the addresses of cells to w in the output format.
+func writeCells(w io.Writer, cells iter.Seq[q3m.Cell]) error {
+	bw := bufio.NewWriter(w)
+	switch outputFormat {
+	case formatCSV:
+		cw := csv.NewWriter(bw)
+		if err := cw.Write([]string{"address", "lat", "lon"}); err != nil {
+			return err
+		}
+		for c := range cells {
+			p := c.Center()
+			err := cw.Write([]string{
+				c.Address().String(),
+				strconv.FormatFloat(p.Lat, 'f', 7, 64),
+				strconv.FormatFloat(p.Lon, 'f', 7, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	case formatNDJSON:
+		enc := json.NewEncoder(bw)
+		for c := range cells {
+			p := c.Center()
+			if err := enc.Encode(newEncodeResult(c.Address(), p.Lat, p.Lon)); err != nil {
+				return err
+			}
+		}
+	case formatJSON:
+		// An array written element by element.
+		bw.WriteString("[")
+		sep := ""
+		for c := range cells {
+			p := c.Center()
+			r, err := json.Marshal(newEncodeResult(c.Address(), p.Lat, p.Lon))
+			if err != nil {
+				return err
+			}
+			bw.WriteString(sep)
+			bw.Write(r)
+			sep = ","
+		}
+		bw.WriteString("]\n")
+	case formatGeoJSON:
+		// A feature collection written feature by feature.
+		bw.WriteString(`{"type":"FeatureCollection","features":[`)
+		sep := ""
+		for c := range cells {
+			f, err := json.Marshal(c.GeoJSON())
+			if err != nil {
+				return err
+			}
+			bw.WriteString(sep)
+			bw.Write(f)
+			sep = ","
+		}
+		bw.WriteString("]}\n")
+	default:
+		for c := range cells {
+			fmt.Fprintln(bw, c.Address())
+		}
+	}
+	return bw.Flush()
+}
+
+func init() {
+	cellsCmd.Flags().StringVar(&cellsBBox, "bbox", "", "rectangle WGS84 ouest,sud,est,nord (lon/lat, ordre GeoJSON)")
+	cellsCmd.Flags().BoolVar(&cellsLambert, "lambert", false, "--bbox en Lambert93: emin,nmin,emax,nmax")
+	cellsCmd.Flags().StringVar(&cellsPolygon, "polygon", "", "fichier GeoJSON (Polygon ou MultiPolygon), - pour l'entrée standard")
+	cellsCmd.Flags().IntVar(&cellsMax, "max", 100_000, "nombre maximal de cellules")
+	rootCmd.AddCommand(cellsCmd)
+}
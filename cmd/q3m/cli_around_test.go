@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCLIAroundText(t *testing.T) {
+	bin := buildBinary(t)
+	encOut, _, _ := runCLI(t, bin, "encode", "48.8584", "2.2945")
+	addr := strings.TrimSpace(encOut)
+
+	out, _, code := runCLI(t, bin, "around", "48.8584", "2.2945")
+	if code != 0 {
+		t.Fatalf("around exited %d", code)
+	}
+	if !strings.Contains(out, "["+addr+"]") {
+		t.Errorf("around output should highlight %s, got:\n%s", addr, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 { // N, 3 rows, S
+		t.Errorf("around output has %d lines, want 5:\n%s", len(lines), out)
+	}
+}
+
+func TestCLIAroundJSON(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "around", "48.8584", "2.2945", "--radius", "2", "--json")
+	if code != 0 {
+		t.Fatalf("around --json exited %d", code)
+	}
+	var result struct {
+		Address string      `json:"address"`
+		Radius  int         `json:"radius"`
+		Rows    [][]*string `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Radius != 2 || len(result.Rows) != 5 {
+		t.Fatalf("radius=%d rows=%d, want 2 and 5", result.Radius, len(result.Rows))
+	}
+	if c := result.Rows[2][2]; c == nil || *c != result.Address {
+		t.Errorf("centre cell = %v, want %s", c, result.Address)
+	}
+}
+
+func TestCLIAroundInvalidRadius(t *testing.T) {
+	bin := buildBinary(t)
+	_, _, code := runCLI(t, bin, "around", "48.8584", "2.2945", "--radius", "-1")
+	if code != exitInvalidArg {
+		t.Errorf("around --radius -1 exited %d, want %d", code, exitInvalidArg)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ikarius/q3m"
+)
+
+func TestCLIAudit(t *testing.T) {
+	bin := buildBinary(t)
+	out, stderr, code := runCLI(t, bin, "audit")
+	if code != 0 || stderr != "" {
+		t.Fatalf("audit exited %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.Contains(out, "\nabatE: abattais, abattait\n") {
+		t.Errorf("audit lacks the abattais group:\n%s", strings.Join(lines[:5], "\n"))
+	}
+	if !strings.HasSuffix(lines[len(lines)-1], "partagent une clé phonétique") {
+		t.Errorf("audit summary = %q", lines[len(lines)-1])
+	}
+
+	out, _, code = runCLI(t, bin, "audit", "--json")
+	var res auditResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil || code != 0 {
+		t.Fatalf("audit --json = %.200q (exit %d): %v", out, code, err)
+	}
+	if res.Words != q3m.DictSize || len(res.Groups) != len(lines)-1 || res.Shared == 0 || res.Shared > res.Words {
+		t.Errorf("audit --json: %d words, %d shared, %d groups", res.Words, res.Shared, len(res.Groups))
+	}
+}
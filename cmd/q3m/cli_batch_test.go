@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// runCLIStdin runs the binary with stdin fed from input.
+func runCLIStdin(t *testing.T, bin, input string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	code := 0
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else {
+			t.Fatalf("run %v: %v", args, err)
+		}
+	}
+	return stdout.String(), stderr.String(), code
+}
+
+func readCSV(t *testing.T, s string, comma rune) [][]string {
+	t.Helper()
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v\n%s", err, s)
+	}
+	return rows
+}
+
+func TestCLIBatchEncodeStdin(t *testing.T) {
+	bin := buildBinary(t)
+	input := "id,name,lat,lon\n1,eiffel,48.8584,2.2945\n2,\"sea, open\",0,0\n3,bad,abc,2\n"
+	out, _, code := runCLIStdin(t, bin, input, "batch", "encode")
+	if code != 0 {
+		t.Fatalf("batch encode exited %d", code)
+	}
+	rows := readCSV(t, out, ',')
+	want := [][]string{
+		{"id", "name", "lat", "lon", "address", "error"},
+		{"1", "eiffel", "48.8584", "2.2945", "province.shootons.retirons", ""},
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4:\n%s", len(rows), out)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+	if rows[2][1] != "sea, open" || rows[2][4] != "" || !strings.Contains(rows[2][5], "outside") {
+		t.Errorf("out-of-grid row = %v", rows[2])
+	}
+	if rows[3][5] == "" {
+		t.Errorf("invalid latitude row should report an error: %v", rows[3])
+	}
+}
+
+func TestCLIBatchDecodeTSVFile(t *testing.T) {
+	bin := buildBinary(t)
+	path := filepath.Join(t.TempDir(), "in.tsv")
+	input := "address\tid\nprovince.shootons.retirons\t1\nfoo.bar.baz\t2\n"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, _, code := runCLI(t, bin, "batch", "decode", path)
+	if code != 0 {
+		t.Fatalf("batch decode exited %d", code)
+	}
+	rows := readCSV(t, out, '\t')
+	if len(rows) != 3 || strings.Join(rows[0], ",") != "address,id,lat,lon,error" {
+		t.Fatalf("output = %v", rows)
+	}
+	if rows[1][2] != "48.858398" || rows[1][3] != "2.294503" || rows[1][4] != "" {
+		t.Errorf("decoded row = %v", rows[1])
+	}
+	if !strings.Contains(rows[2][4], "unknown word") {
+		t.Errorf("error row = %v", rows[2])
+	}
+}
+
+func TestCLIBatchColumnsByIndex(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLIStdin(t, bin, "x;48.8584;2.2945\n", "batch", "encode",
+		"--no-header", "--delimiter", ";", "--lat", "2", "--lon", "3")
+	if code != 0 {
+		t.Fatalf("batch encode exited %d", code)
+	}
+	if strings.TrimSpace(out) != "x;48.8584;2.2945;province.shootons.retirons;" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestCLIBatchUnknownColumn(t *testing.T) {
+	bin := buildBinary(t)
+	_, _, code := runCLIStdin(t, bin, "a,b\n1,2\n", "batch", "encode")
+	if code != exitInvalidArg {
+		t.Errorf("exit code = %d, want %d", code, exitInvalidArg)
+	}
+}
+
+func TestProcessRowsKeepsOrder(t *testing.T) {
+	rows := make([][]string, 1000)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i)}
+	}
+	double := func(f []string) ([]string, error) {
+		n, _ := strconv.Atoi(f[0])
+		return []string{strconv.Itoa(2 * n)}, nil
+	}
+	processRows(rows, []int{0}, 1, double, 7)
+	for i, r := range rows {
+		if len(r) != 3 || r[0] != strconv.Itoa(i) || r[1] != strconv.Itoa(2*i) || r[2] != "" {
+			t.Fatalf("row %d = %v", i, r)
+		}
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	header := []string{"id", " Lat ", "lon"}
+	cases := []struct {
+		sel  string
+		want int
+		ok   bool
+	}{
+		{"lat", 1, true},
+		{"LON", 2, true},
+		{"1", 0, true},
+		{"3", 2, true},
+		{"4", 0, false},
+		{"0", 0, false},
+		{"address", 0, false},
+	}
+	for _, c := range cases {
+		got, err := columnIndex(header, c.sel)
+		if (err == nil) != c.ok || (c.ok && got != c.want) {
+			t.Errorf("columnIndex(%q) = %d, %v; want %d, ok=%v", c.sel, got, err, c.want, c.ok)
+		}
+	}
+}
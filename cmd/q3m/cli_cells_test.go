@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cellsBox is a box of 5 x 7 cells next to the Tour Eiffel, in WGS84.
+const cellsBox = "2.2944,48.8583,2.29445,48.85835"
+
+func TestCLICellsBBox(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "cells", "--bbox", cellsBox)
+	if code != 0 {
+		t.Fatalf("cells exited %d", code)
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 35 {
+		t.Fatalf("cells = %d addresses, want 35", len(lines))
+	}
+
+	out, _, code = runCLI(t, bin, "cells", "--bbox", cellsBox, "--format", "csv")
+	if code != 0 {
+		t.Fatalf("cells --format csv exited %d", code)
+	}
+	rows := strings.Split(strings.TrimSpace(out), "\n")
+	if rows[0] != "address,lat,lon" || len(rows) != 36 || !strings.HasPrefix(rows[1], lines[0]+",48.858") {
+		t.Errorf("cells --format csv = %q...", rows[:2])
+	}
+
+	out, _, code = runCLI(t, bin, "cells", "--bbox", cellsBox, "--json")
+	var all []encodeResult
+	if err := json.Unmarshal([]byte(out), &all); code != 0 || err != nil || len(all) != 35 || all[0].Address != lines[0] {
+		t.Errorf("cells --json = %.200q (exit %d): %v", out, code, err)
+	}
+
+	out, _, code = runCLI(t, bin, "cells", "--bbox", cellsBox, "--format", "ndjson")
+	if code != 0 {
+		t.Fatalf("cells --format ndjson exited %d", code)
+	}
+	rows = strings.Split(strings.TrimSpace(out), "\n")
+	var first encodeResult
+	if err := json.Unmarshal([]byte(rows[0]), &first); err != nil || first.Address != lines[0] || len(rows) != 35 {
+		t.Errorf("cells --format ndjson = %q, %v", rows[0], err)
+	}
+}
+
+func TestCLICellsLambertGeoJSON(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "cells", "--bbox", "648200,6862200,648203,6862202", "--lambert", "--format", "geojson")
+	if code != 0 {
+		t.Fatalf("cells --format geojson exited %d", code)
+	}
+	var fc geoJSONOutput
+	if err := json.Unmarshal([]byte(out), &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v\n%s", err, out)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 6 || fc.Features[0].Geometry.Type != "Polygon" {
+		t.Errorf("cells --format geojson = %+v", fc)
+	}
+}
+
+func TestCLICellsPolygon(t *testing.T) {
+	bin := buildBinary(t)
+	polygon := `{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":` +
+		`[[[2.2944,48.8583],[2.29445,48.8583],[2.29445,48.85835],[2.2944,48.85835],[2.2944,48.8583]]]}}`
+	path := filepath.Join(t.TempDir(), "zone.geojson")
+	if err := os.WriteFile(path, []byte(polygon), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, _, code := runCLI(t, bin, "cells", "--polygon", path)
+	if code != 0 {
+		t.Fatalf("cells --polygon exited %d", code)
+	}
+	bbox, _, _ := runCLI(t, bin, "cells", "--bbox", cellsBox)
+	if fromFile != bbox {
+		t.Errorf("cells --polygon differs from the same --bbox")
+	}
+	fromStdin, _, code := runCLIStdin(t, bin, polygon, "cells", "--polygon", "-")
+	if code != 0 || fromStdin != bbox {
+		t.Errorf("cells --polygon - exited %d", code)
+	}
+}
+
+func TestCLICellsErrors(t *testing.T) {
+	bin := buildBinary(t)
+	for _, args := range [][]string{
+		{"cells"},
+		{"cells", "--bbox", cellsBox, "--polygon", "-"},
+		{"cells", "--bbox", "2.29,48.85,2.30"},
+		{"cells", "--bbox", "2.30,48.85,2.29,48.86"},
+		{"cells", "--bbox", cellsBox, "--max", "10"},
+		{"cells", "--polygon", filepath.Join(t.TempDir(), "absent.geojson")},
+	} {
+		out, _, code := runCLI(t, bin, args...)
+		if code != exitInvalidArg || out != "" {
+			t.Errorf("%v exited %d with %q, want %d and no output", args, code, out, exitInvalidArg)
+		}
+	}
+}
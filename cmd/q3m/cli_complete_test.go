@@ -0,0 +1,32 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCLICompleteAddress(t *testing.T) {
+	bin := buildBinary(t)
+	tests := []struct {
+		args      []string
+		want      string
+		directive string
+	}{
+		{[]string{"__complete", "decode", "province.shoo"}, "province.shootons.", ":6"},
+		{[]string{"__complete", "decode", "province.shootons.retir"}, "province.shootons.retirons", ":4"},
+		{[]string{"__complete", "within", "provi"}, "province.", ":6"},
+	}
+	for _, tt := range tests {
+		out, _, code := runCLI(t, bin, tt.args...)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if code != 0 || !slices.Contains(lines, tt.want) || lines[len(lines)-1] != tt.directive {
+			t.Errorf("%v = %q (exit %d), want %q and directive %s", tt.args, out, code, tt.want, tt.directive)
+		}
+	}
+
+	out, _, _ := runCLI(t, bin, "__complete", "decode", "provx.a")
+	if strings.TrimSpace(out) != ":1" {
+		t.Errorf("completion after an unknown word = %q, want the error directive", out)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCLIDistanceText(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "distance", "province.shootons.retirons", "48.8530,2.3499")
+	if code != 0 {
+		t.Fatalf("distance exited %d", code)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(out), "°") || !strings.Contains(out, " m, cap ") {
+		t.Errorf("distance output = %q, want 'D m, cap B°'", out)
+	}
+}
+
+func TestCLIDistanceJSON(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "distance", "province.shootons.retirons", "48.8530, 2.3499", "--json")
+	if code != 0 {
+		t.Fatalf("distance --json exited %d", code)
+	}
+	var result distanceResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.From.Address != "province.shootons.retirons" || result.To.Address != "" {
+		t.Errorf("distance ends = %+v, %+v", result.From, result.To)
+	}
+	if result.Distance < 4000 || result.Distance > 4200 || result.Bearing < 90 || result.Bearing > 120 {
+		t.Errorf("distance = %+v", result)
+	}
+}
+
+func TestCLIDistanceErrors(t *testing.T) {
+	bin := buildBinary(t)
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"distance", "48.85,abc", "48.85,2.35"}, exitInvalidArg},
+		{[]string{"distance", "province.zzzz.retirons", "48.85,2.35"}, exitUnknownWord},
+	} {
+		if _, _, code := runCLI(t, bin, tt.args...); code != tt.code {
+			t.Errorf("%v exited %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLIErrorExitCodes(t *testing.T) {
+	bin := buildBinary(t)
+	encOut, _, _ := runCLI(t, bin, "encode", "48.8584", "2.2945")
+	parts := strings.Split(strings.TrimSpace(encOut), ".")
+	if len(parts) != 3 {
+		t.Fatalf("encode output = %q", encOut)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"invalid argument", []string{"encode", "abc", "2.0"}, exitInvalidArg},
+		{"invalid format", []string{"decode", "one.two"}, exitInvalidFormat},
+		{"unknown word", []string{"decode", parts[0] + ".xyzzy." + parts[2]}, exitUnknownWord},
+		{"invalid cell", []string{"decode", "zoos.zoos.zoos"}, exitInvalidCell},
+		{"out of grid", []string{"encode", "0", "0"}, exitOutOfGrid},
+		{"strict in Switzerland", []string{"encode", "46.948", "7.4474", "--strict"}, exitOutOfCoverage},
+		{"strict at sea", []string{"encode", "42.5", "4.5", "--strict"}, exitOutOfCoverage},
+		{"strict in France", []string{"encode", "48.8584", "2.2945", "--strict"}, 0},
+		{"not strict in Switzerland", []string{"encode", "46.948", "7.4474"}, 0},
+	}
+	for _, c := range cases {
+		_, _, code := runCLI(t, bin, c.args...)
+		if code != c.code {
+			t.Errorf("%s: exit code = %d, want %d", c.name, code, c.code)
+		}
+	}
+}
+
+func TestCLIErrorJSON(t *testing.T) {
+	bin := buildBinary(t)
+	encOut, _, _ := runCLI(t, bin, "encode", "48.8584", "2.2945")
+	parts := strings.Split(strings.TrimSpace(encOut), ".")
+
+	out, _, code := runCLI(t, bin, "decode", parts[0]+"."+parts[1]+".xyzzy", "--json")
+	if code != exitUnknownWord {
+		t.Fatalf("exit code = %d, want %d", code, exitUnknownWord)
+	}
+	var result struct {
+		Error struct {
+			Category string `json:"category"`
+			Message  string `json:"message"`
+			Position int    `json:"position"`
+			Token    string `json:"token"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Error.Category != "unknown_word" || result.Error.Position != 3 || result.Error.Token != "xyzzy" {
+		t.Errorf("error object = %+v", result.Error)
+	}
+
+	out, _, code = runCLI(t, bin, "encode", "0", "0", "--json")
+	if code != exitOutOfGrid {
+		t.Fatalf("exit code = %d, want %d", code, exitOutOfGrid)
+	}
+	var grid map[string]map[string]any
+	if err := json.Unmarshal([]byte(out), &grid); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	for _, key := range []string{"category", "message", "lat", "lon", "e", "n"} {
+		if _, ok := grid["error"][key]; !ok {
+			t.Errorf("missing key %q in error object: %s", key, out)
+		}
+	}
+}
+
+func TestCLIDecodeSuggest(t *testing.T) {
+	bin := buildBinary(t)
+	_, stderr, code := runCLI(t, bin, "decode", "provinxe.shootons.retirons", "--suggest")
+	if code != exitUnknownWord {
+		t.Fatalf("exit code = %d, want %d", code, exitUnknownWord)
+	}
+	if !strings.Contains(stderr, "province") {
+		t.Errorf("stderr = %q, want suggestion 'province'", stderr)
+	}
+
+	out, _, _ := runCLI(t, bin, "decode", "provinxe.shootons.retirons", "--json")
+	var result struct {
+		Error struct {
+			Suggestions []struct {
+				Position   int `json:"position"`
+				Candidates []struct {
+					Word string `json:"word"`
+				} `json:"candidates"`
+			} `json:"suggestions"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	s := result.Error.Suggestions
+	if len(s) != 1 || s[0].Position != 1 || len(s[0].Candidates) == 0 || s[0].Candidates[0].Word != "province" {
+		t.Errorf("suggestions = %+v, want province at position 1", s)
+	}
+}
+
+func TestCLIKeyFile(t *testing.T) {
+	bin := buildBinary(t)
+	key := filepath.Join(t.TempDir(), "q3m.key")
+	if err := os.WriteFile(key, []byte("nid de gypaète, ne pas diffuser\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, code := runCLI(t, bin, "encode", "48.8584", "2.2945", "--key-file", key)
+	private := strings.TrimSpace(out)
+	if code != 0 || !strings.HasPrefix(private, "~") {
+		t.Fatalf("encode --key-file = %q (exit %d), want a ~ address", private, code)
+	}
+
+	out, _, code = runCLI(t, bin, "decode", private, "--key-file", key)
+	if code != 0 || !strings.HasPrefix(out, "48.858") {
+		t.Errorf("decode --key-file = %q (exit %d)", out, code)
+	}
+	for _, args := range [][]string{
+		{"encode", "48.8584", "2.2945"},
+		{"decode", private},
+	} {
+		out, _, code := runCLI(t, bin, append(args, "--key-file", key, "--format", "geojson")...)
+		if code != 0 || strings.Contains(out, "province.shootons.retirons") || strings.Count(out, private) != 2 {
+			t.Errorf("%s --format geojson with key = %s (exit %d), want only the private address", args[0], out, code)
+		}
+	}
+	if _, _, code = runCLI(t, bin, "decode", private); code != exitNamespace {
+		t.Errorf("decode without key: exit %d, want %d", code, exitNamespace)
+	}
+	if _, _, code = runCLI(t, bin, "decode", "province.shootons.retirons", "--key-file", key); code != exitNamespace {
+		t.Errorf("decode of a public address with key: exit %d, want %d", code, exitNamespace)
+	}
+
+	short := filepath.Join(t.TempDir(), "short.key")
+	os.WriteFile(short, []byte("court"), 0o600)
+	if _, _, code = runCLI(t, bin, "encode", "48.8584", "2.2945", "--key-file", short); code != exitInvalidArg {
+		t.Errorf("short key: exit %d, want %d", code, exitInvalidArg)
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	binary := append(bytes.Repeat([]byte{0xff, 0x00}, 8), '\r', '\n')
+	tests := []struct {
+		name      string
+		data, key []byte
+	}{
+		{"text", []byte("nid de gypaète, ne pas diffuser"), []byte("nid de gypaète, ne pas diffuser")},
+		{"text with LF", []byte("nid de gypaète, ne pas diffuser\n"), []byte("nid de gypaète, ne pas diffuser")},
+		{"text with CRLF", []byte("nid de gypaète, ne pas diffuser\r\n"), []byte("nid de gypaète, ne pas diffuser")},
+		{"text with two LF", []byte("nid de gypaète, ne pas diffuser\n\n"), []byte("nid de gypaète, ne pas diffuser\n")},
+		{"binary", binary, binary},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "q3m.key")
+		if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		key, err := readKeyFile(path)
+		if err != nil || !bytes.Equal(key, tt.key) {
+			t.Errorf("%s: readKeyFile = %q, %v, want %q", tt.name, key, err, tt.key)
+		}
+	}
+}
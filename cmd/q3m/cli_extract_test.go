@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCLIExtract(t *testing.T) {
+	bin := buildBinary(t)
+	const text = "je suis au Province.Shootons.Retirons près du portail, ou re:abjurer.ramassis.rossant."
+	out, _, code := runCLIStdin(t, bin, text, "extract")
+	if code != 0 {
+		t.Fatalf("extract exited %d", code)
+	}
+	var results []extractResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(results) != 2 || results[0].Address != "province.shootons.retirons" || results[1].Region != "re" {
+		t.Fatalf("extract = %+v", results)
+	}
+	if r := results[0]; text[r.Start:r.End] != r.Text || r.Text != "Province.Shootons.Retirons" || r.Lat < 48.85 {
+		t.Errorf("first match = %+v", r)
+	}
+
+	out, _, code = runCLIStdin(t, bin, text, "extract", "--format", "ndjson")
+	if code != 0 || strings.Count(out, "\n") != 2 {
+		t.Errorf("extract --format ndjson = %q (exit %d)", out, code)
+	}
+	if out, _, _ := runCLIStdin(t, bin, "rien ici", "extract"); strings.TrimSpace(out) != "[]" {
+		t.Errorf("extract without address = %q, want []", out)
+	}
+
+	out, _, code = runCLIStdin(t, bin, "RDV province.shootons.retirons.", "extract", "--linkify", "markdown", "--url", "https://example.org/{address}")
+	if want := "RDV [province.shootons.retirons](https://example.org/province.shootons.retirons)."; code != 0 || out != want {
+		t.Errorf("extract --linkify markdown = %q, want %q", out, want)
+	}
+	if _, _, code := runCLIStdin(t, bin, "", "extract", "--linkify", "pdf"); code != exitInvalidArg {
+		t.Errorf("extract --linkify pdf exited %d, want %d", code, exitInvalidArg)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type geoJSONOutput struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry struct {
+			Type string `json:"type"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+func runGeoJSON(t *testing.T, bin string, args ...string) geoJSONOutput {
+	t.Helper()
+	out, _, code := runCLI(t, bin, args...)
+	if code != 0 {
+		t.Fatalf("%v exited %d", args, code)
+	}
+	var fc geoJSONOutput
+	if err := json.Unmarshal([]byte(out), &fc); err != nil {
+		t.Fatalf("invalid GeoJSON: %v\n%s", err, out)
+	}
+	if fc.Type != "FeatureCollection" {
+		t.Fatalf("type = %q, want FeatureCollection", fc.Type)
+	}
+	return fc
+}
+
+func TestCLIEncodeGeoJSON(t *testing.T) {
+	bin := buildBinary(t)
+	fc := runGeoJSON(t, bin, "encode", "48.8584", "2.2945", "--format", "geojson")
+	if len(fc.Features) != 2 {
+		t.Fatalf("got %d features, want 2", len(fc.Features))
+	}
+	if fc.Features[0].Geometry.Type != "Point" || fc.Features[1].Geometry.Type != "Polygon" {
+		t.Errorf("geometries = %s, %s; want Point, Polygon",
+			fc.Features[0].Geometry.Type, fc.Features[1].Geometry.Type)
+	}
+	for _, f := range fc.Features {
+		for _, key := range []string{"address", "w1", "w2", "w3"} {
+			if _, ok := f.Properties[key]; !ok {
+				t.Errorf("missing property %q", key)
+			}
+		}
+	}
+}
+
+func TestCLIDecodeGeoJSON(t *testing.T) {
+	bin := buildBinary(t)
+	fc := runGeoJSON(t, bin, "decode", "province.shootons.retirons", "--format", "geojson")
+	if len(fc.Features) != 2 || fc.Features[1].Properties["address"] != "province.shootons.retirons" {
+		t.Errorf("features = %+v", fc.Features)
+	}
+
+	out, _, _ := runCLI(t, bin, "encode", "48.8584", "2.2945", "--precision", "1cm")
+	addr := strings.TrimSpace(out)
+	fc = runGeoJSON(t, bin, "decode", addr, "--format", "geojson")
+	if len(fc.Features) != 2 || fc.Features[1].Properties["address"] != addr || fc.Features[1].Properties["w4"] == nil {
+		t.Errorf("features of %s = %+v, want its 1cm sub-cell", addr, fc.Features)
+	}
+}
+
+func TestCLIAroundGeoJSON(t *testing.T) {
+	bin := buildBinary(t)
+	fc := runGeoJSON(t, bin, "around", "48.8584", "2.2945", "--format", "geojson")
+	if len(fc.Features) != 9 {
+		t.Errorf("got %d features, want 9", len(fc.Features))
+	}
+}
+
+func TestCLIFormatUnsupported(t *testing.T) {
+	bin := buildBinary(t)
+	_, _, code := runCLI(t, bin, "info", "--format", "geojson")
+	if code != exitInvalidArg {
+		t.Errorf("info --format geojson exited %d, want %d", code, exitInvalidArg)
+	}
+	out, _, code := runCLI(t, bin, "info", "--format", "json")
+	if code != 0 {
+		t.Fatalf("info --format json exited %d", code)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Errorf("--format json should behave like --json: %v\n%s", err, out)
+	}
+}
@@ -188,3 +188,158 @@ func TestMain(m *testing.M) {
 	os.Chdir(".")
 	os.Exit(m.Run())
 }
+
+func TestCLIEncodeResolution(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "encode", "48.8584", "2.2945", "--resolution", "1km")
+	if code != 0 {
+		t.Fatalf("encode --resolution exited %d", code)
+	}
+	addr := strings.TrimSpace(out)
+	words, res, ok := strings.Cut(addr, "@")
+	if !ok || res != "1km" || strings.Count(words, ".") != 1 {
+		t.Fatalf("encode --resolution 1km = %q, want w1.w2@1km", addr)
+	}
+
+	out, _, code = runCLI(t, bin, "decode", addr, "--json")
+	if code != 0 {
+		t.Fatalf("decode %s exited %d", addr, code)
+	}
+	var result decodeResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Address != addr || result.Resolution.String() != "1km" || result.W3 != "" {
+		t.Errorf("decode %s = %+v", addr, result)
+	}
+
+	if _, _, code := runCLI(t, bin, "encode", "48.8584", "2.2945", "--resolution", "5m"); code != exitInvalidArg {
+		t.Errorf("encode --resolution 5m exited %d, want %d", code, exitInvalidArg)
+	}
+}
+
+func TestCLIEncodePrecision(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLI(t, bin, "encode", "48.8584", "2.2945", "--precision", "1cm")
+	if code != 0 {
+		t.Fatalf("encode --precision exited %d", code)
+	}
+	addr := strings.TrimSpace(out)
+	if !strings.HasPrefix(addr, "province.shootons.retirons.") || strings.Count(addr, ".") != 3 {
+		t.Fatalf("encode --precision 1cm = %q, want the 1m address and a fourth word", addr)
+	}
+
+	for _, tt := range []struct{ address, precision string }{
+		{addr, "1cm"},
+		{"province.shootons.retirons", "1m"},
+	} {
+		out, _, code = runCLI(t, bin, "decode", tt.address, "--json")
+		if code != 0 {
+			t.Fatalf("decode %s exited %d", tt.address, code)
+		}
+		var result decodeResult
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if result.Address != tt.address || result.Precision.String() != tt.precision {
+			t.Errorf("decode %s = %+v, want precision %s", tt.address, result, tt.precision)
+		}
+	}
+
+	if _, _, code := runCLI(t, bin, "encode", "48.8584", "2.2945", "--precision", "1cm", "--resolution", "1km"); code != exitInvalidArg {
+		t.Errorf("encode --precision 1cm --resolution 1km exited %d, want %d", code, exitInvalidArg)
+	}
+}
+
+func TestCLIEncodeRegion(t *testing.T) {
+	bin := buildBinary(t)
+	for _, args := range [][]string{
+		{"encode", "--", "-20.8789", "55.4481"},
+		{"encode", "--strict", "--", "-20.8789", "55.4481"},
+	} {
+		out, _, code := runCLI(t, bin, args...)
+		if code != 0 {
+			t.Fatalf("%v exited %d", args, code)
+		}
+		addr := strings.TrimSpace(out)
+		if !strings.HasPrefix(addr, "re:") {
+			t.Fatalf("%v = %q, want a La Réunion address", args, addr)
+		}
+
+		out, _, code = runCLI(t, bin, "decode", addr, "--json")
+		if code != 0 {
+			t.Fatalf("decode %s exited %d", addr, code)
+		}
+		var result decodeResult
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if result.Region != "re" || result.Lat > -20.87 || result.Lat < -20.89 {
+			t.Errorf("decode %s = %+v", addr, result)
+		}
+	}
+}
+
+func TestCLIDecodeLenient(t *testing.T) {
+	bin := buildBinary(t)
+	out, stderr, code := runCLI(t, bin, "decode", "///Province Shootons Retirons")
+	if code != 0 || strings.TrimSpace(out) != "48.858398, 2.294503" {
+		t.Fatalf("decode of a pasted address = %q (exit %d)", out, code)
+	}
+	if !strings.Contains(stderr, "province.shootons.retirons (case, separator, prefix)") {
+		t.Errorf("decode stderr = %q, want the normalizations", stderr)
+	}
+
+	out, _, code = runCLI(t, bin, "decode", "province-shootons-retirons", "--json")
+	var result decodeResult
+	if err := json.Unmarshal([]byte(out), &result); code != 0 || err != nil {
+		t.Fatalf("decode --json exited %d: %v\n%s", code, err, out)
+	}
+	if result.Address != "province.shootons.retirons" || len(result.Normalized) != 1 || result.Normalized[0] != "separator" {
+		t.Errorf("decode --json = %+v", result)
+	}
+
+	if _, stderr, _ := runCLI(t, bin, "decode", "province.shootons.retirons"); stderr != "" {
+		t.Errorf("decode of a canonical address wrote %q", stderr)
+	}
+}
+
+func TestCLIDecodePhonetic(t *testing.T) {
+	bin := buildBinary(t)
+	if _, _, code := runCLI(t, bin, "decode", "provinse.chootons.retiront"); code != exitUnknownWord {
+		t.Errorf("decode without --phonetic exited %d, want %d", code, exitUnknownWord)
+	}
+	out, stderr, code := runCLI(t, bin, "decode", "--phonetic", "provinse.chootons.retiront")
+	if code != 0 || strings.TrimSpace(out) != "48.858398, 2.294503" {
+		t.Fatalf("decode --phonetic = %q (exit %d)", out, code)
+	}
+	if !strings.Contains(stderr, "province.shootons.retirons (phonetic)") {
+		t.Errorf("decode --phonetic stderr = %q", stderr)
+	}
+
+	out, _, code = runCLI(t, bin, "decode", "--phonetic", "Provinse Shootons Retirons", "--json")
+	var result decodeResult
+	if err := json.Unmarshal([]byte(out), &result); code != 0 || err != nil {
+		t.Fatalf("decode --phonetic --json exited %d: %v\n%s", code, err, out)
+	}
+	if result.Address != "province.shootons.retirons" || joinNormalizations(result.Normalized) != "case, separator, phonetic" {
+		t.Errorf("decode --phonetic --json = %+v", result)
+	}
+
+	// Both zone and zones sound like "zonne": no reading is guessed.
+	out, stderr, code = runCLI(t, bin, "decode", "--phonetic", "province.shootons.zonne")
+	if code != exitUnknownWord || out != "" || !strings.Contains(stderr, "province.shootons.zone, province.shootons.zones") {
+		t.Errorf("decode --phonetic of an ambiguous word = %q, %q (exit %d)", out, stderr, code)
+	}
+	out, _, _ = runCLI(t, bin, "decode", "--phonetic", "province.shootons.zonne", "--json")
+	var failure struct {
+		Error errorInfo `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &failure); err != nil || failure.Error.Position != 3 || len(failure.Error.Candidates) != 2 {
+		t.Errorf("decode --phonetic --json of an ambiguous word = %s", out)
+	}
+
+	if _, stderr, code := runCLI(t, bin, "decode", "--phonetic", "zzqx.shootons.retirons"); code != exitUnknownWord || !strings.Contains(stderr, "zzqx") {
+		t.Errorf("decode --phonetic of a word without sound-alike = %q (exit %d)", stderr, code)
+	}
+}
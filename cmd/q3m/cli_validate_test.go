@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ikarius/q3m"
+)
+
+func TestCLIValidate(t *testing.T) {
+	bin := buildBinary(t)
+	last := q3m.WordAt(q3m.DictSize - 1)
+	tests := []struct {
+		address string
+		code    int
+		first   string
+	}{
+		{"province.shootons.retirons", 0, "valide"},
+		{"cachiez.vendras.sombra", exitOutOfCoverage, "valide, hors de la couverture terrestre"},
+		{"provinxe.shootons.retirons", exitUnknownWord, "invalide: "},
+		{last + "." + last + "." + last, exitInvalidCell, "invalide: "},
+		{"province.shootons", exitInvalidFormat, "invalide: "},
+	}
+	for _, tt := range tests {
+		out, stderr, code := runCLI(t, bin, "validate", tt.address)
+		if code != tt.code || !strings.HasPrefix(out, tt.first) || stderr != "" {
+			t.Errorf("validate %s = %q, %q (exit %d), want %q (exit %d)", tt.address, out, stderr, code, tt.first, tt.code)
+		}
+	}
+
+	out, _, code := runCLI(t, bin, "validate", "provinxe.shootons.retirons", "--json")
+	var res q3m.ValidationResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil || code != exitUnknownWord {
+		t.Fatalf("validate --json = %q (exit %d): %v", out, code, err)
+	}
+	if res.Valid || res.Reason != q3m.ReasonUnknownWord || len(res.Words) != 3 || res.Words[0].Known || !res.Words[1].Known {
+		t.Errorf("validate --json = %+v", res)
+	}
+}
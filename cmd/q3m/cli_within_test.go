@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const withinZonesGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"paris","depot":"D1"},"geometry":{"type":"Polygon","coordinates":
+	[[[2.25,48.83],[2.35,48.83],[2.35,48.88],[2.25,48.88],[2.25,48.83]]]}},
+{"type":"Feature","properties":{"name":"champ-de-mars","depot":"D2"},"geometry":{"type":"Polygon","coordinates":
+	[[[2.29,48.85],[2.30,48.85],[2.30,48.86],[2.29,48.86],[2.29,48.85]]]}},
+{"type":"Feature","properties":{"name":"marseille","depot":"D3"},"geometry":{"type":"Polygon","coordinates":
+	[[[5.3,43.2],[5.4,43.2],[5.4,43.3],[5.3,43.3],[5.3,43.2]]]}}
+]}`
+
+func writeZones(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "zones.geojson")
+	if err := os.WriteFile(path, []byte(withinZonesGeoJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCLIWithin(t *testing.T) {
+	bin := buildBinary(t)
+	zones := writeZones(t)
+
+	out, _, code := runCLI(t, bin, "within", "province.shootons.retirons", "--zones", zones)
+	if code != 0 {
+		t.Fatalf("within exited %d", code)
+	}
+	if got := strings.Fields(out); len(got) != 2 || got[0] != "paris" || got[1] != "champ-de-mars" {
+		t.Errorf("within = %q, want paris and champ-de-mars", out)
+	}
+
+	out, _, code = runCLI(t, bin, "within", "province.shootons.retirons", "--zones", zones, "--name-key", "depot", "--json")
+	if code != 0 {
+		t.Fatalf("within --json exited %d", code)
+	}
+	var result withinResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Address != "province.shootons.retirons" || strings.Join(result.Zones, ",") != "D1,D2" {
+		t.Errorf("within --json = %+v", result)
+	}
+
+	// Lyon is in no zone.
+	lyon, _, _ := runCLI(t, bin, "encode", "45.76", "4.84")
+	out, _, code = runCLIStdin(t, bin, withinZonesGeoJSON, "within", strings.TrimSpace(lyon), "--zones", "-", "--json")
+	if code != 0 || !strings.Contains(out, `"zones":[]`) {
+		t.Errorf("within outside every zone = %q, exit %d", out, code)
+	}
+}
+
+func TestCLIWithinErrors(t *testing.T) {
+	bin := buildBinary(t)
+	zones := writeZones(t)
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"within", "province.shootons.retirons"}, exitInvalidArg},
+		{[]string{"within", "province.shootons.retirons", "--zones", filepath.Join(t.TempDir(), "absent.geojson")}, exitInvalidArg},
+		{[]string{"within", "province.zzzz.retirons", "--zones", zones}, exitUnknownWord},
+	} {
+		if _, _, code := runCLI(t, bin, tt.args...); code != tt.code {
+			t.Errorf("%v exited %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
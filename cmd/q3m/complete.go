@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// completeAddress is the shell completion of an address argument: it
+// completes the word being typed, appending the dot of the next word
+// until the address is complete.
+func completeAddress(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// --key-file, if given, selects the codec of private addresses.
+	if cmd.PreRunE != nil && cmd.PreRunE(cmd, args) != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	texts, err := codec().Autocomplete(toComplete, 0)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	directive := cobra.ShellCompDirectiveNoFileComp
+	for i, t := range texts {
+		if _, err := codec().ParseAddress(t); err != nil {
+			texts[i] = t + "."
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+	}
+	return texts, directive
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -10,41 +12,177 @@ import (
 )
 
 var decodeCmd = &cobra.Command{
-	Use:   "decode <mot1.mot2.mot3>",
-	Short: "Décode une adresse q3m en coordonnées GPS",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		coord, err := q3m.Decode(args[0])
+	Use:               "decode <mot1.mot2.mot3[.mot4][@résolution]>",
+	Short:             "Décode une adresse q3m en coordonnées GPS",
+	Args:              streamArgs(1),
+	ValidArgsFunction: completeAddress,
+	Annotations:       map[string]string{formatsAnnotation: formatGeoJSON},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if streamMode {
+			return runStream(decodeStreamLine)
+		}
+		res, err := decodeAddress(args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: %v\n", err)
-			os.Exit(1)
+			if suggest && !jsonOutput && errors.Is(err, q3m.ErrUnknownWord) {
+				printSuggestions(args[0])
+			}
+			return err
+		}
+		coord := q3m.Coordinate{Lat: res.Lat, Lon: res.Lon}
+		addr := res.address()
+		if len(res.Normalized) > 0 && outputFormat == formatText {
+			fmt.Fprintf(os.Stderr, "adresse lue comme %s (%s)\n", addr, joinNormalizations(res.Normalized))
 		}
 
-		if jsonOutput {
-			addr := strings.ToLower(strings.TrimSpace(args[0]))
-			parts := strings.Split(addr, ".")
-			out := struct {
-				Lat     float64 `json:"lat"`
-				Lon     float64 `json:"lon"`
-				Address string  `json:"address"`
-				W1      string  `json:"w1"`
-				W2      string  `json:"w2"`
-				W3      string  `json:"w3"`
-			}{
-				Lat:     coord.Lat,
-				Lon:     coord.Lon,
-				Address: addr,
-				W1:      parts[0],
-				W2:      parts[1],
-				W3:      parts[2],
+		switch outputFormat {
+		case formatGeoJSON:
+			cell, err := codec().GeoJSON(addr.String())
+			if err != nil {
+				return err
 			}
-			writeJSON(out)
-		} else {
-			fmt.Printf("%.6f, %.6f\n", coord.Lat, coord.Lon)
+			writeJSON(q3m.NewFeatureCollection(
+				q3m.PointFeature(coord, addr.Properties()),
+				cell,
+			))
+		case formatJSON:
+			writeJSON(res)
+		default:
+			// 1e-6 degree is about 10cm: print more digits for finer
+			// sub-cells.
+			d := 6 + int(res.Precision)
+			fmt.Printf("%.*f, %.*f\n", d, coord.Lat, d, coord.Lon)
 		}
+		return nil
 	},
 }
 
+var (
+	suggest  bool
+	phonetic bool
+)
+
+// decodeResult is the JSON shape of a decoded address.
+type decodeResult struct {
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Address string  `json:"address"`
+	W1      string  `json:"w1"`
+	W2      string  `json:"w2"`
+	W3      string  `json:"w3"`
+	W4      string  `json:"w4,omitempty"`
+	Region  string  `json:"region,omitempty"`
+	Private bool    `json:"private,omitempty"`
+
+	Resolution q3m.Resolution `json:"resolution,omitempty"`
+	Precision  q3m.Precision  `json:"precision"`
+
+	// Normalized lists the changes made to the address as given, if any.
+	Normalized []q3m.Normalization `json:"normalized,omitempty"`
+}
+
+// address returns the decoded address.
+func (r decodeResult) address() q3m.Address {
+	return q3m.Address{W1: r.W1, W2: r.W2, W3: r.W3, W4: r.W4, Region: r.Region, Private: r.Private, Resolution: r.Resolution}
+}
+
+// decodeAddress decodes address into its JSON result.
+func decodeAddress(address string) (decodeResult, error) {
+	s, normalized := q3m.NormalizeAddress(address)
+	addr, err := codec().ParseAddress(s)
+	if phonetic && errors.Is(err, q3m.ErrUnknownWord) {
+		// The error of PhoneticAddress names the word without a
+		// sound-alike.
+		if addr, err = codec().PhoneticAddress(address); err == nil {
+			s = addr.String()
+			normalized = append(normalized, q3m.NormalizedPhonetic)
+		}
+	}
+	if err != nil {
+		return decodeResult{}, err
+	}
+	coord, prec, err := codec().DecodePrecise(s)
+	if err != nil {
+		return decodeResult{}, err
+	}
+	return decodeResult{
+		Lat:     coord.Lat,
+		Lon:     coord.Lon,
+		Address: addr.String(),
+		W1:      addr.W1,
+		W2:      addr.W2,
+		W3:      addr.W3,
+		W4:      addr.W4,
+		Region:  addr.Region,
+		Private: addr.Private,
+
+		Resolution: addr.Resolution,
+		Precision:  prec,
+		Normalized: normalized,
+	}, nil
+}
+
+// joinNormalizations lists normalizations for a message.
+func joinNormalizations(ns []q3m.Normalization) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = string(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+// decodeRequest is the JSON form of a decode request (--stream, serve).
+type decodeRequest struct {
+	ID      json.RawMessage `json:"id,omitempty"`
+	Address *string         `json:"address"`
+}
+
+func (r decodeRequest) run() (decodeResult, error) {
+	if r.Address == nil {
+		return decodeResult{}, &argError{name: "requête", err: errors.New("champ address requis")}
+	}
+	return decodeAddress(*r.Address)
+}
+
+// decodeStreamLine handles one {"address":..} request of decode --stream.
+func decodeStreamLine(line []byte) (json.RawMessage, any, error) {
+	var req decodeRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return nil, nil, &argError{name: "JSON", err: err}
+	}
+	res, err := req.run()
+	if err != nil {
+		return req.ID, nil, err
+	}
+	return req.ID, struct {
+		ID json.RawMessage `json:"id,omitempty"`
+		decodeResult
+	}{req.ID, res}, nil
+}
+
+// printSuggestions lists spelling corrections for the unknown words of
+// address on stderr.
+func printSuggestions(address string) {
+	sugg, err := q3m.Suggest(address, maxSuggestions)
+	if err != nil {
+		return
+	}
+	for _, s := range sugg {
+		words := make([]string, len(s.Candidates))
+		for i, c := range s.Candidates {
+			words[i] = c.Word
+		}
+		if len(words) == 0 {
+			fmt.Fprintf(os.Stderr, "aucune suggestion pour %q (mot %d)\n", s.Token, s.Position)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "suggestions pour %q (mot %d): %s\n", s.Token, s.Position, strings.Join(words, ", "))
+	}
+}
+
 func init() {
+	decodeCmd.Flags().BoolVar(&streamMode, "stream", false, "lit des requêtes NDJSON sur l'entrée standard")
+	decodeCmd.Flags().BoolVar(&suggest, "suggest", false, "propose des corrections pour les mots inconnus")
+	decodeCmd.Flags().BoolVar(&phonetic, "phonetic", false, "accepte les mots inconnus qui se prononcent comme un mot du dictionnaire")
 	rootCmd.AddCommand(decodeCmd)
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+var distanceCmd = &cobra.Command{
+	Use:   "distance <adresse|lat,lon> <adresse|lat,lon>",
+	Short: "Calcule la distance géodésique et le cap entre deux points",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		from, err := parsePoint(args[0])
+		if err != nil {
+			return err
+		}
+		to, err := parsePoint(args[1])
+		if err != nil {
+			return err
+		}
+
+		d, bearing := q3m.Geodesic(from.coord(), to.coord())
+
+		if jsonOutput {
+			writeJSON(distanceResult{From: from, To: to, Distance: d, Bearing: bearing})
+		} else {
+			fmt.Printf("%.3f m, cap %.2f°\n", d, bearing)
+		}
+		return nil
+	},
+}
+
+// point is an end of a distance: coordinates, or the centre of the cell of
+// an address.
+type point struct {
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Address string  `json:"address,omitempty"`
+}
+
+func (p point) coord() q3m.Coordinate {
+	return q3m.Coordinate{Lat: p.Lat, Lon: p.Lon}
+}
+
+// parsePoint parses "lat,lon" or a q3m address.
+func parsePoint(s string) (point, error) {
+	if lat, lon, ok := strings.Cut(s, ","); ok {
+		la, err1 := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		lo, err2 := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if err1 != nil || err2 != nil {
+			return point{}, &argError{name: "point", err: fmt.Errorf("%q (lat,lon ou adresse)", s)}
+		}
+		return point{Lat: la, Lon: lo}, nil
+	}
+	res, err := decodeAddress(s)
+	if err != nil {
+		return point{}, err
+	}
+	return point{Lat: res.Lat, Lon: res.Lon, Address: res.Address}, nil
+}
+
+// distanceResult is the JSON shape of a distance.
+type distanceResult struct {
+	From     point   `json:"from"`
+	To       point   `json:"to"`
+	Distance float64 `json:"distance_m"`
+	Bearing  float64 `json:"bearing_deg"`
+}
+
+func init() {
+	rootCmd.AddCommand(distanceCmd)
+}
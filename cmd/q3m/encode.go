@@ -1,59 +1,168 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/ikarius/q3m"
 	"github.com/spf13/cobra"
 )
 
 var encodeCmd = &cobra.Command{
-	Use:   "encode <lat> <lon>",
-	Short: "Encode des coordonnées GPS en adresse q3m",
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		lat, err := strconv.ParseFloat(args[0], 64)
+	Use:         "encode <lat> <lon>",
+	Short:       "Encode des coordonnées GPS en adresse q3m",
+	Args:        streamArgs(2),
+	Annotations: map[string]string{formatsAnnotation: formatGeoJSON},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if streamMode {
+			return runStream(encodeStreamLine)
+		}
+		lat, err := parseFloatArg("latitude", args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "latitude invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
-		lon, err := strconv.ParseFloat(args[1], 64)
+		lon, err := parseFloatArg("longitude", args[1])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "longitude invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
 
-		addr, err := q3m.Encode(lat, lon)
+		addr, err := encodeAt(lat, lon, encodeResolution, encodePrecision)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: %v\n", err)
-			os.Exit(1)
+			return err
 		}
 
-		if jsonOutput {
-			out := struct {
-				Address string  `json:"address"`
-				W1      string  `json:"w1"`
-				W2      string  `json:"w2"`
-				W3      string  `json:"w3"`
-				Lat     float64 `json:"lat"`
-				Lon     float64 `json:"lon"`
-			}{
-				Address: addr.String(),
-				W1:      addr.W1,
-				W2:      addr.W2,
-				W3:      addr.W3,
-				Lat:     lat,
-				Lon:     lon,
+		switch outputFormat {
+		case formatGeoJSON:
+			cell, err := codec().GeoJSON(addr.String())
+			if err != nil {
+				return err
 			}
-			writeJSON(out)
-		} else {
+			writeJSON(q3m.NewFeatureCollection(
+				q3m.PointFeature(q3m.Coordinate{Lat: lat, Lon: lon}, addr.Properties()),
+				cell,
+			))
+		case formatJSON:
+			writeJSON(newEncodeResult(addr, lat, lon))
+		default:
 			fmt.Println(addr)
 		}
+		return nil
 	},
 }
 
+var (
+	encodeResolution, encodePrecision string
+
+	strictCoverage bool
+)
+
+// encodeAt encodes (lat, lon) at the resolution and precision named by the
+// flags or the fields of a request.
+func encodeAt(lat, lon float64, resolution, precision string) (q3m.Address, error) {
+	res, err := q3m.ParseResolution(resolution)
+	if err != nil {
+		return q3m.Address{}, &argError{name: "résolution", err: fmt.Errorf("%q (1m, 10m, 100m ou 1km)", resolution)}
+	}
+	prec, err := q3m.ParsePrecision(precision)
+	if err != nil {
+		return q3m.Address{}, &argError{name: "précision", err: fmt.Errorf("%q (1m, 10cm ou 1cm)", precision)}
+	}
+	c := codec()
+	if _, ok := q3m.RegionOf(lat, lon); ok && keyFile == "" {
+		// --strict checks the metropolitan coverage only: overseas points
+		// keep the grid of their region.
+		c = q3m.DefaultCodec()
+	}
+	if prec == q3m.Precision1m {
+		return c.EncodeAt(lat, lon, res)
+	}
+	if res != q3m.Res1m {
+		return q3m.Address{}, &argError{name: "précision", err: fmt.Errorf("%s incompatible avec la résolution %s", prec, res)}
+	}
+	return c.EncodePrecise(lat, lon, prec)
+}
+
+// encodeResult is the JSON shape of an encoded position.
+type encodeResult struct {
+	Address string  `json:"address"`
+	W1      string  `json:"w1"`
+	W2      string  `json:"w2"`
+	W3      string  `json:"w3"`
+	W4      string  `json:"w4,omitempty"`
+	Region  string  `json:"region,omitempty"`
+	Private bool    `json:"private,omitempty"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+
+	Resolution q3m.Resolution `json:"resolution,omitempty"`
+}
+
+func newEncodeResult(addr q3m.Address, lat, lon float64) encodeResult {
+	return encodeResult{
+		Address: addr.String(),
+		W1:      addr.W1,
+		W2:      addr.W2,
+		W3:      addr.W3,
+		W4:      addr.W4,
+		Region:  addr.Region,
+		Private: addr.Private,
+		Lat:     lat,
+		Lon:     lon,
+
+		Resolution: addr.Resolution,
+	}
+}
+
+// encodeRequest is the JSON form of an encode request (--stream, serve).
+// Without a resolution or precision, --resolution and --precision apply.
+type encodeRequest struct {
+	ID         json.RawMessage `json:"id,omitempty"`
+	Lat        *float64        `json:"lat"`
+	Lon        *float64        `json:"lon"`
+	Resolution *string         `json:"resolution,omitempty"`
+	Precision  *string         `json:"precision,omitempty"`
+}
+
+func (r encodeRequest) run() (encodeResult, error) {
+	if r.Lat == nil || r.Lon == nil {
+		return encodeResult{}, &argError{name: "requête", err: errors.New("champs lat et lon requis")}
+	}
+	res, prec := encodeResolution, encodePrecision
+	if r.Resolution != nil {
+		res = *r.Resolution
+	}
+	if r.Precision != nil {
+		prec = *r.Precision
+	}
+	addr, err := encodeAt(*r.Lat, *r.Lon, res, prec)
+	if err != nil {
+		return encodeResult{}, err
+	}
+	return newEncodeResult(addr, *r.Lat, *r.Lon), nil
+}
+
+// encodeStreamLine handles one {"lat":..,"lon":..} request of encode --stream.
+func encodeStreamLine(line []byte) (json.RawMessage, any, error) {
+	var req encodeRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return nil, nil, &argError{name: "JSON", err: err}
+	}
+	res, err := req.run()
+	if err != nil {
+		return req.ID, nil, err
+	}
+	return req.ID, struct {
+		ID json.RawMessage `json:"id,omitempty"`
+		encodeResult
+	}{req.ID, res}, nil
+}
+
 func init() {
+	encodeCmd.Flags().StringVar(&encodeResolution, "resolution", "1m", "taille des cellules: 1m, 10m, 100m ou 1km")
+	encodeCmd.Flags().StringVar(&encodePrecision, "precision", "1m", "précision d'un quatrième mot: 1m (aucun), 10cm ou 1cm")
+	encodeCmd.Flags().BoolVar(&strictCoverage, "strict", false, "refuse les points en mer ou à l'étranger")
+	encodeCmd.Flags().BoolVar(&streamMode, "stream", false, "lit des requêtes NDJSON sur l'entrée standard")
 	rootCmd.AddCommand(encodeCmd)
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ikarius/q3m"
+)
+
+// Exit codes, one per error category so that scripts can branch on them.
+const (
+	exitFailure       = 1 // usage or unexpected error
+	exitInvalidArg    = 2 // unparsable command-line argument
+	exitInvalidFormat = 3
+	exitUnknownWord   = 4
+	exitInvalidCell   = 5
+	exitOutOfGrid     = 6
+	exitNamespace     = 7 // private address without its key, or the reverse
+	exitOutOfCoverage = 8 // encode --strict or validate at sea or abroad
+)
+
+// argError reports a command-line argument that could not be parsed.
+type argError struct {
+	name string
+	err  error
+}
+
+func (e *argError) Error() string {
+	return fmt.Sprintf("%s invalide: %v", e.name, e.err)
+}
+
+func (e *argError) Unwrap() error {
+	return e.err
+}
+
+// exitCode is the error of a command that has already reported its
+// outcome and only sets the exit code.
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("code de sortie %d", int(e))
+}
+
+// errorInfo is the machine-readable error object printed with --json.
+type errorInfo struct {
+	Category string   `json:"category"`
+	Message  string   `json:"message"`
+	Position int      `json:"position,omitempty"`
+	Token    string   `json:"token,omitempty"`
+	Lat      *float64 `json:"lat,omitempty"`
+	Lon      *float64 `json:"lon,omitempty"`
+	E        *float64 `json:"e,omitempty"`
+	N        *float64 `json:"n,omitempty"`
+
+	Suggestions []q3m.WordSuggestions `json:"suggestions,omitempty"`
+	// Candidates are the readings of a word with several sound-alikes
+	// (decode --phonetic).
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// maxSuggestions is the number of candidates proposed per unknown word.
+const maxSuggestions = 5
+
+// classifyError maps err to its category, exit code and JSON description.
+func classifyError(err error) (errorInfo, int) {
+	info := errorInfo{Message: err.Error()}
+
+	var ae *q3m.AddressError
+	var oe *q3m.OutOfGridError
+	var ce *q3m.OutOfCoverageError
+	var arg *argError
+	switch {
+	case errors.As(err, &ae):
+		info.Category = string(ae.Reason)
+		info.Position = ae.Position
+		info.Token = ae.Token
+		switch ae.Reason {
+		case q3m.ReasonUnknownWord:
+			info.Suggestions, _ = q3m.Suggest(ae.Address, maxSuggestions)
+			var amb *q3m.AmbiguousWordError
+			if errors.As(err, &amb) {
+				for _, a := range amb.Candidates {
+					info.Candidates = append(info.Candidates, a.String())
+				}
+			}
+			return info, exitUnknownWord
+		case q3m.ReasonInvalidCell:
+			return info, exitInvalidCell
+		case q3m.ReasonNamespace:
+			return info, exitNamespace
+		default:
+			return info, exitInvalidFormat
+		}
+	case errors.As(err, &oe):
+		info.Category = "out_of_grid"
+		info.Lat, info.Lon, info.E, info.N = &oe.Lat, &oe.Lon, &oe.E, &oe.N
+		return info, exitOutOfGrid
+	case errors.As(err, &ce):
+		info.Category = "out_of_coverage"
+		info.Lat, info.Lon, info.E, info.N = &ce.Lat, &ce.Lon, &ce.E, &ce.N
+		return info, exitOutOfCoverage
+	case errors.As(err, &arg):
+		info.Category = "invalid_argument"
+		return info, exitInvalidArg
+	default:
+		info.Category = "failure"
+		return info, exitFailure
+	}
+}
+
+// reportError prints err (as a JSON object with --json) and returns the
+// process exit code.
+func reportError(err error) int {
+	var ec exitCode
+	if errors.As(err, &ec) {
+		return int(ec)
+	}
+	info, code := classifyError(err)
+	if outputFormat != formatText {
+		writeJSON(struct {
+			Error errorInfo `json:"error"`
+		}{info})
+		return code
+	}
+	if info.Category == "failure" {
+		// Cobra usage errors are printed as-is.
+		fmt.Fprintln(os.Stderr, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "erreur: %v\n", err)
+	}
+	return code
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+// Link formats of extract --linkify.
+var linkFormats = map[string]q3m.LinkFormat{
+	"markdown": q3m.LinkMarkdown,
+	"html":     q3m.LinkHTML,
+}
+
+var (
+	extractLinkify string
+	extractURL     string
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract [fichier]",
+	Short: "Repère les adresses q3m d'un texte libre",
+	Long: "Lit un texte (ou l'entrée standard) et écrit en JSON les adresses q3m\n" +
+		"qu'il contient, avec leur position en octets et leurs coordonnées.\n" +
+		"Avec --linkify, écrit le texte où chaque adresse devient un lien.",
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{formatsAnnotation: formatNDJSON},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		var url func(q3m.Match) string
+		if extractURL != "" {
+			url = func(m q3m.Match) string { return expandURL(extractURL, m) }
+		}
+		format, ok := linkFormats[extractLinkify]
+		if extractLinkify != "" && !ok {
+			return &argError{name: "linkify", err: fmt.Errorf("%q (markdown ou html)", extractLinkify)}
+		}
+
+		in := os.Stdin
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return &argError{name: "fichier", err: err}
+			}
+			defer f.Close()
+			in = f
+		}
+		text, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+
+		if extractLinkify != "" {
+			fmt.Print(q3m.Linkify(string(text), format, url))
+			return nil
+		}
+		results := []extractResult{}
+		for _, m := range q3m.FindAddresses(string(text)) {
+			results = append(results, extractResult{
+				Start:        m.Start,
+				End:          m.End,
+				Text:         string(text[m.Start:m.End]),
+				encodeResult: newEncodeResult(m.Address, m.Coordinate.Lat, m.Coordinate.Lon),
+			})
+		}
+		if outputFormat != formatNDJSON {
+			writeJSON(results)
+			return nil
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// extractResult is the JSON shape of an address found in a text.
+type extractResult struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+	encodeResult
+}
+
+// expandURL replaces {lat}, {lon} and {address} in the --url template.
+func expandURL(template string, m q3m.Match) string {
+	return strings.NewReplacer(
+		"{lat}", strconv.FormatFloat(m.Coordinate.Lat, 'f', 7, 64),
+		"{lon}", strconv.FormatFloat(m.Coordinate.Lon, 'f', 7, 64),
+		"{address}", m.Address.String(),
+	).Replace(template)
+}
+
+func init() {
+	extractCmd.Flags().StringVar(&extractLinkify, "linkify", "", "écrit le texte avec des liens: markdown ou html")
+	extractCmd.Flags().StringVar(&extractURL, "url", "", "modèle des liens, avec {lat}, {lon} et {address} (geo:lat,lon par défaut)")
+	rootCmd.AddCommand(extractCmd)
+}
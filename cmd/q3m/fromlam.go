@@ -2,8 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/ikarius/q3m"
 	"github.com/spf13/cobra"
@@ -13,39 +11,36 @@ var fromlamCmd = &cobra.Command{
 	Use:   "fromlam <E> <N>",
 	Short: "Convertit des coordonnées Lambert93 en WGS84",
 	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		E, err := strconv.ParseFloat(args[0], 64)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		E, err := parseFloatArg("coordonnée E", args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: coordonnée E invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
-		N, err := strconv.ParseFloat(args[1], 64)
+		N, err := parseFloatArg("coordonnée N", args[1])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: coordonnée N invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
 
 		lat, lon := q3m.FromLambert93(E, N)
 
 		if jsonOutput {
-			out := struct {
-				Lat float64 `json:"lat"`
-				Lon float64 `json:"lon"`
-				E   float64 `json:"e"`
-				N   float64 `json:"n"`
-			}{
-				Lat: lat,
-				Lon: lon,
-				E:   E,
-				N:   N,
-			}
-			writeJSON(out)
+			writeJSON(fromlamResult{Lat: lat, Lon: lon, E: E, N: N})
 		} else {
 			fmt.Printf("%.6f, %.6f\n", lat, lon)
 		}
+		return nil
 	},
 }
 
+// fromlamResult is the JSON shape of a Lambert93 to WGS84 conversion.
+type fromlamResult struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	E   float64 `json:"e"`
+	N   float64 `json:"n"`
+}
+
 func init() {
 	rootCmd.AddCommand(fromlamCmd)
 }
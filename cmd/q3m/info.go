@@ -12,30 +12,7 @@ var infoCmd = &cobra.Command{
 	Short: "Affiche les paramètres de la grille q3m",
 	Run: func(cmd *cobra.Command, args []string) {
 		if jsonOutput {
-			out := struct {
-				Projection string `json:"projection"`
-				EMin       int    `json:"emin"`
-				EMax       int    `json:"emax"`
-				NMin       int    `json:"nmin"`
-				NMax       int    `json:"nmax"`
-				GridWidth  uint64 `json:"grid_width"`
-				GridHeight uint64 `json:"grid_height"`
-				TotalCells uint64 `json:"total_cells"`
-				DictSize   int    `json:"dict_size"`
-				Precision  string `json:"precision"`
-			}{
-				Projection: "Lambert93/EPSG:2154",
-				EMin:       int(q3m.EMin),
-				EMax:       int(q3m.EMax),
-				NMin:       int(q3m.NMin),
-				NMax:       int(q3m.NMax),
-				GridWidth:  q3m.GridWidth,
-				GridHeight: q3m.GridHeight,
-				TotalCells: q3m.TotalCells,
-				DictSize:   q3m.DictSize,
-				Precision:  "1m x 1m",
-			}
-			writeJSON(out)
+			writeJSON(newInfoResult())
 		} else {
 			fmt.Println("q3m - géocodage en 3 mots")
 			fmt.Println()
@@ -47,10 +24,48 @@ var infoCmd = &cobra.Command{
 			fmt.Printf("Total:         %d cellules\n", q3m.TotalCells)
 			fmt.Printf("Dictionnaire:  %d mots\n", q3m.DictSize)
 			fmt.Println("Précision:     1m x 1m")
+			cov := q3m.DefaultCoverage()
+			fmt.Printf("Couverture:    %d km² (France métropolitaine et Corse, marge de %d m)\n",
+				int(cov.Area()/1e6), int(cov.Buffer()))
 		}
 	},
 }
 
+// infoResult is the JSON shape of the grid parameters.
+type infoResult struct {
+	Projection string `json:"projection"`
+	EMin       int    `json:"emin"`
+	EMax       int    `json:"emax"`
+	NMin       int    `json:"nmin"`
+	NMax       int    `json:"nmax"`
+	GridWidth  uint64 `json:"grid_width"`
+	GridHeight uint64 `json:"grid_height"`
+	TotalCells uint64 `json:"total_cells"`
+	DictSize   int    `json:"dict_size"`
+	Precision  string `json:"precision"`
+
+	CoverageArea   int `json:"coverage_km2"`
+	CoverageBuffer int `json:"coverage_buffer_m"`
+}
+
+func newInfoResult() infoResult {
+	return infoResult{
+		Projection: "Lambert93/EPSG:2154",
+		EMin:       int(q3m.EMin),
+		EMax:       int(q3m.EMax),
+		NMin:       int(q3m.NMin),
+		NMax:       int(q3m.NMax),
+		GridWidth:  q3m.GridWidth,
+		GridHeight: q3m.GridHeight,
+		TotalCells: q3m.TotalCells,
+		DictSize:   q3m.DictSize,
+		Precision:  "1m x 1m",
+
+		CoverageArea:   int(q3m.DefaultCoverage().Area() / 1e6),
+		CoverageBuffer: int(q3m.DefaultCoverage().Buffer()),
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(infoCmd)
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+var keyFile string
+
+// customCodec is the codec configured by --key-file and --strict, nil
+// without them.
+var customCodec *q3m.Codec
+
+// codec returns the codec of encode and decode.
+func codec() *q3m.Codec {
+	if customCodec != nil {
+		return customCodec
+	}
+	return q3m.DefaultCodec()
+}
+
+// setupCodec builds the codec of encode and decode from --key-file and
+// --strict, if given.
+func setupCodec(cmd *cobra.Command, args []string) error {
+	var opts []q3m.Option
+	if keyFile != "" {
+		cmd.SilenceUsage = true
+		secret, err := readKeyFile(keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, q3m.WithSecret(secret))
+	}
+	if strictCoverage {
+		opts = append(opts, q3m.WithCoveragePolicy(q3m.CoverageReject))
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	var err error
+	customCodec, err = q3m.NewCodec(opts...)
+	return err
+}
+
+// readKeyFile reads the secret of --key-file. A key file holding valid
+// UTF-8 text is a passphrase: one final line break, "\n" or "\r\n", is
+// ignored so that the key can be written with echo. Any other file is a
+// binary key, used byte for byte.
+func readKeyFile(path string) ([]byte, error) {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &argError{name: "fichier de clé", err: err}
+	}
+	if utf8.Valid(secret) {
+		if s, ok := bytes.CutSuffix(secret, []byte("\n")); ok {
+			secret, _ = bytes.CutSuffix(s, []byte("\r"))
+		}
+	}
+	if len(secret) < q3m.MinSecretSize {
+		return nil, &argError{
+			name: "fichier de clé",
+			err:  fmt.Errorf("%d octets, au moins %d requis", len(secret), q3m.MinSecretSize),
+		}
+	}
+	return secret, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{encodeCmd, decodeCmd} {
+		c.Flags().StringVar(&keyFile, "key-file", "", "clé secrète (au moins 16 octets) pour des adresses privées ~mot1.mot2.mot3")
+		c.PreRunE = setupCodec
+	}
+}
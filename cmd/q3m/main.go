@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -13,6 +16,19 @@ var version = "dev"
 
 var jsonOutput bool
 
+// Output formats selected with --format.
+const (
+	formatText    = "text"
+	formatJSON    = "json"
+	formatGeoJSON = "geojson"
+)
+
+var outputFormat string
+
+// formatsAnnotation is the command annotation listing, comma-separated, the
+// --format values accepted besides text and json.
+const formatsAnnotation = "q3m:formats"
+
 var rootCmd = &cobra.Command{
 	Use:     "q3m",
 	Short:   "q3m - géocodage en 3 mots pour la France métropolitaine",
@@ -21,10 +37,35 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "sortie au format JSON")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "sortie au format JSON (équivaut à --format json)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", formatText, "format de sortie: text, json, geojson, csv ou ndjson selon la commande")
+	rootCmd.PersistentPreRunE = checkFormat
 	rootCmd.SilenceErrors = true
 }
 
+// checkFormat reconciles --json with --format and rejects formats the
+// command does not support.
+func checkFormat(cmd *cobra.Command, args []string) error {
+	if jsonOutput && !cmd.Flags().Changed("format") {
+		outputFormat = formatJSON
+	}
+	supported := []string{formatText, formatJSON}
+	if extra := cmd.Annotations[formatsAnnotation]; extra != "" {
+		supported = append(supported, strings.Split(extra, ",")...)
+	}
+	if !slices.Contains(supported, outputFormat) {
+		cmd.SilenceUsage = true
+		requested := outputFormat
+		outputFormat = formatText
+		return &argError{
+			name: "format",
+			err:  fmt.Errorf("%q non supporté par %s (%s)", requested, cmd.Name(), strings.Join(supported, ", ")),
+		}
+	}
+	jsonOutput = outputFormat == formatJSON
+	return nil
+}
+
 func writeJSON(v any) {
 	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
 		fmt.Fprintf(os.Stderr, "erreur JSON: %v\n", err)
@@ -32,9 +73,17 @@ func writeJSON(v any) {
 	}
 }
 
+// parseFloatArg parses a numeric command-line argument.
+func parseFloatArg(name, s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, &argError{name: name, err: err}
+	}
+	return v, nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(reportError(err))
 	}
 }
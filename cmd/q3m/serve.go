@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// shutdownTimeout bounds the wait for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+var (
+	serveListen   string
+	serveMaxBody  int64
+	serveMaxBatch int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Lance un serveur HTTP exposant l'API q3m",
+	Long: "Expose encode, decode, tolam, fromlam, info, neighbors et suggest\n" +
+		"sous /v1/, avec les mêmes réponses JSON que --json. Les requêtes GET\n" +
+		"prennent leurs paramètres dans l'URL ; les requêtes POST acceptent un\n" +
+		"objet JSON ou un tableau d'objets (lot). La description OpenAPI est\n" +
+		"servie sous /openapi.json.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if serveMaxBody <= 0 || serveMaxBatch <= 0 {
+			return &argError{name: "limite", err: errors.New("--max-body et --max-batch doivent être positifs")}
+		}
+		ln, err := listen(serveListen)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(os.Stderr, "q3m: écoute sur %s\n", ln.Addr())
+		api := &apiServer{maxBody: serveMaxBody, maxBatch: serveMaxBatch}
+		return serveUntil(ctx, ln, api.handler())
+	},
+}
+
+// listen opens a TCP listener, or a Unix socket for "unix:/path".
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	// Remove a stale socket left by a previous run.
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&fs.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	return net.Listen("unix", path)
+}
+
+// serveUntil serves h on ln until ctx is cancelled, then shuts down
+// gracefully, letting in-flight requests complete.
+func serveUntil(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// apiServer holds the limits applied to HTTP requests.
+type apiServer struct {
+	maxBody  int64 // bytes per request body
+	maxBatch int   // items per batch POST
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/encode", endpoint(s, encodeQuery))
+	mux.Handle("/v1/decode", endpoint(s, decodeQuery))
+	mux.Handle("/v1/tolam", endpoint(s, tolamQuery))
+	mux.Handle("/v1/fromlam", endpoint(s, fromlamQuery))
+	mux.Handle("/v1/neighbors", endpoint(s, neighborsQuery))
+	mux.Handle("/v1/suggest", endpoint(s, suggestQuery))
+	mux.HandleFunc("GET /v1/info", func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, http.StatusOK, newInfoResult())
+	})
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	})
+	return mux
+}
+
+// apiRequest is a request accepted by an endpoint.
+type apiRequest[Res any] interface {
+	run() (Res, error)
+}
+
+// apiError is the body of a failed response, or of a failed batch item.
+type apiError struct {
+	Error errorInfo `json:"error"`
+}
+
+// endpoint serves requests of type Req: from the query string for GET, from
+// a JSON object or array of objects (batch) for POST.
+func endpoint[Req apiRequest[Res], Res any](s *apiServer, fromQuery func(url.Values) (Req, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			req, err := fromQuery(r.URL.Query())
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			res, err := req.run()
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeResponse(w, http.StatusOK, res)
+
+		case http.MethodPost:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBody))
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			body = bytes.TrimSpace(body)
+			if len(body) > 0 && body[0] == '[' {
+				serveBatch(s, w, body, func(req Req) (any, error) { return req.run() })
+				return
+			}
+			var req Req
+			if err := json.Unmarshal(body, &req); err != nil {
+				writeError(w, &argError{name: "JSON", err: err})
+				return
+			}
+			res, err := req.run()
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeResponse(w, http.StatusOK, res)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeResponse(w, http.StatusMethodNotAllowed, apiError{errorInfo{
+				Category: "method_not_allowed",
+				Message:  "méthode " + r.Method + " non supportée",
+			}})
+		}
+	}
+}
+
+// serveBatch answers a JSON array of requests with an array of results, in
+// order. Failed items are reported in place as {"error":{...}}.
+func serveBatch[Req any](s *apiServer, w http.ResponseWriter, body []byte, run func(Req) (any, error)) {
+	var reqs []Req
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		writeError(w, &argError{name: "JSON", err: err})
+		return
+	}
+	if len(reqs) > s.maxBatch {
+		writeError(w, fmt.Errorf("%w: %d > %d", errTooManyItems, len(reqs), s.maxBatch))
+		return
+	}
+	out := make([]any, len(reqs))
+	for i, req := range reqs {
+		res, err := run(req)
+		if err != nil {
+			info, _ := classifyError(err)
+			res = apiError{info}
+		}
+		out[i] = res
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// errTooManyItems reports a batch exceeding --max-batch.
+var errTooManyItems = errors.New("trop d'éléments dans le lot")
+
+// writeError writes err as an {"error":{...}} body with a matching status.
+func writeError(w http.ResponseWriter, err error) {
+	var info errorInfo
+	status := http.StatusInternalServerError
+	var mbe *http.MaxBytesError
+	switch {
+	case errors.As(err, &mbe), errors.Is(err, errTooManyItems):
+		info = errorInfo{Category: "request_too_large", Message: err.Error()}
+		status = http.StatusRequestEntityTooLarge
+	default:
+		var code int
+		info, code = classifyError(err)
+		status = httpStatus(code)
+	}
+	writeResponse(w, status, apiError{info})
+}
+
+// httpStatus maps a CLI exit code to the HTTP status of the same category.
+func httpStatus(code int) int {
+	switch code {
+	case exitInvalidArg, exitInvalidFormat:
+		return http.StatusBadRequest
+	case exitUnknownWord, exitInvalidCell, exitOutOfGrid, exitNamespace, exitOutOfCoverage:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// queryFloat returns the float parameter name, or nil if absent.
+func queryFloat(q url.Values, name string) (*float64, error) {
+	s := q.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, &argError{name: name, err: err}
+	}
+	return &v, nil
+}
+
+// queryInt returns the int parameter name, or nil if absent.
+func queryInt(q url.Values, name string) (*int, error) {
+	s := q.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, &argError{name: name, err: err}
+	}
+	return &v, nil
+}
+
+// queryString returns the string parameter name, or nil if absent.
+func queryString(q url.Values, name string) *string {
+	if !q.Has(name) {
+		return nil
+	}
+	s := q.Get(name)
+	return &s
+}
+
+func encodeQuery(q url.Values) (req encodeRequest, err error) {
+	if req.Lat, err = queryFloat(q, "lat"); err != nil {
+		return req, err
+	}
+	req.Lon, err = queryFloat(q, "lon")
+	req.Resolution = queryString(q, "resolution")
+	req.Precision = queryString(q, "precision")
+	return req, err
+}
+
+func decodeQuery(q url.Values) (decodeRequest, error) {
+	return decodeRequest{Address: queryString(q, "address")}, nil
+}
+
+// tolamRequest is the JSON form of a tolam request.
+type tolamRequest struct {
+	Lat *float64 `json:"lat"`
+	Lon *float64 `json:"lon"`
+}
+
+func (r tolamRequest) run() (tolamResult, error) {
+	if r.Lat == nil || r.Lon == nil {
+		return tolamResult{}, &argError{name: "requête", err: errors.New("champs lat et lon requis")}
+	}
+	E, N := q3m.ToLambert93(*r.Lat, *r.Lon)
+	return tolamResult{E: E, N: N, Lat: *r.Lat, Lon: *r.Lon}, nil
+}
+
+func tolamQuery(q url.Values) (req tolamRequest, err error) {
+	if req.Lat, err = queryFloat(q, "lat"); err != nil {
+		return req, err
+	}
+	req.Lon, err = queryFloat(q, "lon")
+	return req, err
+}
+
+// fromlamRequest is the JSON form of a fromlam request.
+type fromlamRequest struct {
+	E *float64 `json:"e"`
+	N *float64 `json:"n"`
+}
+
+func (r fromlamRequest) run() (fromlamResult, error) {
+	if r.E == nil || r.N == nil {
+		return fromlamResult{}, &argError{name: "requête", err: errors.New("champs e et n requis")}
+	}
+	lat, lon := q3m.FromLambert93(*r.E, *r.N)
+	return fromlamResult{Lat: lat, Lon: lon, E: *r.E, N: *r.N}, nil
+}
+
+func fromlamQuery(q url.Values) (req fromlamRequest, err error) {
+	if req.E, err = queryFloat(q, "e"); err != nil {
+		return req, err
+	}
+	req.N, err = queryFloat(q, "n")
+	return req, err
+}
+
+// neighborsRequest is the JSON form of a neighbors request.
+type neighborsRequest struct {
+	Address *string `json:"address"`
+	K       *int    `json:"k"`
+}
+
+// neighborsResult lists the cells at distance k of an address.
+type neighborsResult struct {
+	Address   string   `json:"address"`
+	K         int      `json:"k"`
+	Neighbors []string `json:"neighbors"`
+}
+
+func (r neighborsRequest) run() (neighborsResult, error) {
+	if r.Address == nil {
+		return neighborsResult{}, &argError{name: "requête", err: errors.New("champ address requis")}
+	}
+	k := 1
+	if r.K != nil {
+		k = *r.K
+	}
+	if k < 0 || k > maxAroundRadius {
+		return neighborsResult{}, &argError{name: "k", err: fmt.Errorf("%d hors de [0, %d]", k, maxAroundRadius)}
+	}
+	res, err := decodeAddress(*r.Address)
+	if err != nil {
+		return neighborsResult{}, err
+	}
+	ring, err := q3m.Ring(res.address(), k)
+	if err != nil {
+		return neighborsResult{}, err
+	}
+	out := neighborsResult{Address: res.Address, K: k, Neighbors: make([]string, len(ring))}
+	for i, a := range ring {
+		out.Neighbors[i] = a.String()
+	}
+	return out, nil
+}
+
+func neighborsQuery(q url.Values) (req neighborsRequest, err error) {
+	req.Address = queryString(q, "address")
+	req.K, err = queryInt(q, "k")
+	return req, err
+}
+
+// suggestRequest is the JSON form of a suggest request.
+type suggestRequest struct {
+	Address *string `json:"address"`
+	Max     *int    `json:"max"`
+}
+
+// suggestResult lists the corrections proposed for an address.
+type suggestResult struct {
+	Address     string                `json:"address"`
+	Suggestions []q3m.WordSuggestions `json:"suggestions"`
+}
+
+func (r suggestRequest) run() (suggestResult, error) {
+	if r.Address == nil {
+		return suggestResult{}, &argError{name: "requête", err: errors.New("champ address requis")}
+	}
+	n := maxSuggestions
+	if r.Max != nil {
+		n = *r.Max
+	}
+	sugg, err := q3m.Suggest(*r.Address, n)
+	if err != nil {
+		return suggestResult{}, err
+	}
+	if sugg == nil {
+		sugg = []q3m.WordSuggestions{}
+	}
+	return suggestResult{Address: *r.Address, Suggestions: sugg}, nil
+}
+
+func suggestQuery(q url.Values) (req suggestRequest, err error) {
+	req.Address = queryString(q, "address")
+	req.Max, err = queryInt(q, "max")
+	return req, err
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", `adresse d'écoute TCP, ou "unix:/chemin" pour une socket Unix`)
+	serveCmd.Flags().Int64Var(&serveMaxBody, "max-body", 1<<20, "taille maximale d'un corps de requête (octets)")
+	serveCmd.Flags().IntVar(&serveMaxBatch, "max-batch", 10000, "nombre maximal d'éléments par lot")
+	rootCmd.AddCommand(serveCmd)
+}
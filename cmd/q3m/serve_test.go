@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	api := &apiServer{maxBody: 1 << 10, maxBatch: 3}
+	srv := httptest.NewServer(api.handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// doRequest sends a request and decodes the JSON response into v.
+func doRequest(t *testing.T, method, url, body string, v any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type = %q", method, url, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServeEncodeDecode(t *testing.T) {
+	srv := newTestServer(t)
+
+	var enc encodeResult
+	if code := doRequest(t, "GET", srv.URL+"/v1/encode?lat=48.8584&lon=2.2945", "", &enc); code != 200 {
+		t.Fatalf("GET encode status %d", code)
+	}
+	if enc.Address != "province.shootons.retirons" {
+		t.Errorf("GET encode = %+v", enc)
+	}
+
+	var dec decodeResult
+	if code := doRequest(t, "POST", srv.URL+"/v1/decode", `{"address":"province.shootons.retirons"}`, &dec); code != 200 {
+		t.Fatalf("POST decode status %d", code)
+	}
+	if dec.W1 != "province" || dec.Lat < 48.85 || dec.Lat > 48.86 {
+		t.Errorf("POST decode = %+v", dec)
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+	srv := newTestServer(t)
+
+	var out []map[string]any
+	body := `[{"address":"province.shootons.retirons"},{"address":"a.b"},{"address":"province.shootons.zzzz"}]`
+	if code := doRequest(t, "POST", srv.URL+"/v1/decode", body, &out); code != 200 {
+		t.Fatalf("batch status %d", code)
+	}
+	if len(out) != 3 {
+		t.Fatalf("got %d results, want 3", len(out))
+	}
+	if out[0]["address"] != "province.shootons.retirons" {
+		t.Errorf("item 1 = %v", out[0])
+	}
+	for i, want := range []string{"invalid_format", "unknown_word"} {
+		e, _ := out[i+1]["error"].(map[string]any)
+		if e["category"] != want {
+			t.Errorf("item %d = %v, want category %s", i+2, out[i+1], want)
+		}
+	}
+
+	var tooMany apiError
+	body = `[{"lat":48,"lon":2},{"lat":48,"lon":2},{"lat":48,"lon":2},{"lat":48,"lon":2}]`
+	if code := doRequest(t, "POST", srv.URL+"/v1/encode", body, &tooMany); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized batch status %d, want 413", code)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		category           string
+	}{
+		{"GET", "/v1/encode?lat=abc&lon=2", "", 400, "invalid_argument"},
+		{"GET", "/v1/encode?lat=48", "", 400, "invalid_argument"},
+		{"GET", "/v1/encode?lat=0&lon=0", "", 422, "out_of_grid"},
+		{"GET", "/v1/encode?lat=48.8584&lon=2.2945&resolution=2km", "", 400, "invalid_argument"},
+		{"GET", "/v1/decode?address=a.b", "", 400, "invalid_format"},
+		{"GET", "/v1/decode?address=province.shootons.zzzz", "", 422, "unknown_word"},
+		{"POST", "/v1/decode", "{", 400, "invalid_argument"},
+		{"POST", "/v1/decode", `{"address":"` + strings.Repeat("a", 2000) + `"}`, 413, "request_too_large"},
+		{"DELETE", "/v1/decode", "", 405, "method_not_allowed"},
+		{"GET", "/v1/neighbors?address=province.shootons.retirons&k=99", "", 400, "invalid_argument"},
+	}
+	for _, tt := range tests {
+		var e apiError
+		if code := doRequest(t, tt.method, srv.URL+tt.path, tt.body, &e); code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, code, tt.status)
+		}
+		if e.Error.Category != tt.category {
+			t.Errorf("%s %s: category %q, want %q", tt.method, tt.path, e.Error.Category, tt.category)
+		}
+	}
+}
+
+func TestServeOtherEndpoints(t *testing.T) {
+	srv := newTestServer(t)
+
+	var lam tolamResult
+	doRequest(t, "GET", srv.URL+"/v1/tolam?lat=46.5&lon=3", "", &lam)
+	if lam.E < 699000 || lam.E > 701000 {
+		t.Errorf("tolam E = %.1f, want ~700000", lam.E)
+	}
+	var gps fromlamResult
+	doRequest(t, "POST", srv.URL+"/v1/fromlam", `{"e":700000,"n":6600000}`, &gps)
+	if gps.Lon < 2.99 || gps.Lon > 3.01 {
+		t.Errorf("fromlam lon = %f, want ~3", gps.Lon)
+	}
+
+	var nb neighborsResult
+	doRequest(t, "GET", srv.URL+"/v1/neighbors?address=province.shootons.retirons", "", &nb)
+	if nb.K != 1 || len(nb.Neighbors) != 8 {
+		t.Errorf("neighbors = %+v, want 8 cells at k=1", nb)
+	}
+
+	var sg suggestResult
+	doRequest(t, "GET", srv.URL+"/v1/suggest?address=provinse.shootons.retirons&max=3", "", &sg)
+	if len(sg.Suggestions) != 1 || sg.Suggestions[0].Position != 1 {
+		t.Errorf("suggest = %+v", sg)
+	}
+
+	var info infoResult
+	doRequest(t, "GET", srv.URL+"/v1/info", "", &info)
+	if info.DictSize != 10800 {
+		t.Errorf("info dict_size = %d", info.DictSize)
+	}
+
+	var spec map[string]any
+	doRequest(t, "GET", srv.URL+"/openapi.json", "", &spec)
+	paths, _ := spec["paths"].(map[string]any)
+	for _, p := range []string{"/v1/encode", "/v1/decode", "/v1/tolam", "/v1/fromlam", "/v1/neighbors", "/v1/suggest", "/v1/info"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("openapi.json does not document %s", p)
+		}
+	}
+}
+
+func TestServeUnixSocketShutdown(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "q3m.sock")
+	ln, err := listen("unix:" + sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	api := &apiServer{maxBody: 1 << 10, maxBatch: 1}
+	go func() { done <- serveUntil(ctx, ln, api.handler()) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://q3m/v1/info")
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Errorf("status %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serveUntil: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestServeResolution(t *testing.T) {
+	srv := newTestServer(t)
+
+	var enc encodeResult
+	if code := doRequest(t, "POST", srv.URL+"/v1/encode", `{"lat":48.8584,"lon":2.2945,"resolution":"100m"}`, &enc); code != 200 {
+		t.Fatalf("POST encode status %d", code)
+	}
+	if !strings.HasSuffix(enc.Address, "@100m") || enc.Resolution.String() != "100m" {
+		t.Fatalf("POST encode = %+v", enc)
+	}
+
+	var nb neighborsResult
+	if code := doRequest(t, "GET", srv.URL+"/v1/neighbors?address="+enc.Address, "", &nb); code != 200 {
+		t.Fatalf("GET neighbors status %d", code)
+	}
+	for _, a := range nb.Neighbors {
+		if !strings.HasSuffix(a, "@100m") {
+			t.Errorf("neighbour %q is not a 100m address", a)
+		}
+	}
+}
+
+func TestServeRegion(t *testing.T) {
+	srv := newTestServer(t)
+
+	var enc encodeResult
+	if code := doRequest(t, "GET", srv.URL+"/v1/encode?lat=-20.8789&lon=55.4481", "", &enc); code != 200 {
+		t.Fatalf("GET encode status %d", code)
+	}
+	if enc.Region != "re" || !strings.HasPrefix(enc.Address, "re:") {
+		t.Fatalf("GET encode = %+v", enc)
+	}
+
+	var dec decodeResult
+	if code := doRequest(t, "GET", srv.URL+"/v1/decode?address="+enc.Address, "", &dec); code != 200 {
+		t.Fatalf("GET decode status %d", code)
+	}
+	if dec.Region != "re" || dec.Address != enc.Address || dec.Lat > -20.87 || dec.Lat < -20.89 {
+		t.Errorf("GET decode = %+v", dec)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// maxStreamLine bounds the length of one NDJSON request.
+const maxStreamLine = 1 << 20
+
+var streamMode bool
+
+// streamError is a failed NDJSON response.
+type streamError struct {
+	ID    json.RawMessage `json:"id,omitempty"`
+	Error errorInfo       `json:"error"`
+}
+
+// streamLineFunc handles one NDJSON request and returns its id (echoed in
+// the response) and result.
+type streamLineFunc func(line []byte) (json.RawMessage, any, error)
+
+// streamArgs accepts n positional arguments, or none with --stream.
+func streamArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if streamMode {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	}
+}
+
+// runStream serves NDJSON requests from stdin until EOF.
+func runStream(handle streamLineFunc) error {
+	return serveStream(os.Stdin, os.Stdout, handle)
+}
+
+// serveStream reads one JSON request per line from r and writes one JSON
+// response per line to w, flushing after each so that it can run as a
+// long-lived co-process. Per-request failures are reported as
+// {"error":{...}} lines and do not stop the stream.
+func serveStream(r io.Reader, w io.Writer, handle streamLineFunc) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		id, res, err := handle(line)
+		if err != nil {
+			info, _ := classifyError(err)
+			res = streamError{ID: id, Error: info}
+		}
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeStreamEncode(t *testing.T) {
+	in := strings.NewReader(`{"lat":48.8584,"lon":2.2945,"id":1}` + "\n\n" +
+		`{"lat":0,"lon":0,"id":"b"}` + "\n" +
+		"not json\n" +
+		`{"lat":48.8584}` + "\n")
+	var out strings.Builder
+	if err := serveStream(in, &out, encodeStreamLine); err != nil {
+		t.Fatalf("serveStream: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), out.String())
+	}
+
+	var ok map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &ok); err != nil {
+		t.Fatalf("line 1: %v", err)
+	}
+	if ok["address"] != "province.shootons.retirons" || ok["id"] != 1.0 {
+		t.Errorf("line 1 = %s", lines[0])
+	}
+
+	wantCategories := []string{"out_of_grid", "invalid_argument", "invalid_argument"}
+	for i, want := range wantCategories {
+		var e streamError
+		if err := json.Unmarshal([]byte(lines[i+1]), &e); err != nil {
+			t.Fatalf("line %d: %v", i+2, err)
+		}
+		if e.Error.Category != want {
+			t.Errorf("line %d category = %q, want %q", i+2, e.Error.Category, want)
+		}
+	}
+	if !strings.HasPrefix(lines[1], `{"id":"b",`) {
+		t.Errorf("error line should echo the request id: %s", lines[1])
+	}
+}
+
+func TestServeStreamDecode(t *testing.T) {
+	in := strings.NewReader(`{"address":"province.shootons.retirons"}` + "\n" + `{"address":"a.b"}` + "\n")
+	var out strings.Builder
+	if err := serveStream(in, &out, decodeStreamLine); err != nil {
+		t.Fatalf("serveStream: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var res decodeResult
+	if err := json.Unmarshal([]byte(lines[0]), &res); err != nil || res.W3 != "retirons" {
+		t.Errorf("line 1 = %s (%v)", lines[0], err)
+	}
+	var e streamError
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil || e.Error.Category != "invalid_format" {
+		t.Errorf("line 2 = %s (%v)", lines[1], err)
+	}
+}
+
+func TestServeStreamFlushesEachLine(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStream(inR, outW, encodeStreamLine)
+		outW.Close()
+	}()
+
+	// Like a co-process: each response must arrive before the next request.
+	resp := bufio.NewReader(outR)
+	for i := 0; i < 3; i++ {
+		if _, err := io.WriteString(inW, `{"lat":48.8584,"lon":2.2945}`+"\n"); err != nil {
+			t.Fatal(err)
+		}
+		got := make(chan string, 1)
+		go func() {
+			line, _ := resp.ReadString('\n')
+			got <- line
+		}()
+		select {
+		case line := <-got:
+			if !strings.Contains(line, "province.shootons.retirons") {
+				t.Fatalf("response %d = %q", i, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no response to request %d: output not flushed", i)
+		}
+	}
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("serveStream: %v", err)
+	}
+}
+
+func TestCLIStream(t *testing.T) {
+	bin := buildBinary(t)
+	out, _, code := runCLIStdin(t, bin, `{"lat":48.8584,"lon":2.2945}`+"\n"+`{"lat":0,"lon":0}`+"\n", "encode", "--stream")
+	if code != 0 {
+		t.Fatalf("encode --stream exited %d", code)
+	}
+	if n := strings.Count(out, "\n"); n != 2 {
+		t.Errorf("got %d lines, want 2:\n%s", n, out)
+	}
+	_, _, code = runCLI(t, bin, "decode", "--stream", "province.shootons.retirons")
+	if code == 0 {
+		t.Error("decode --stream with a positional argument should fail")
+	}
+}
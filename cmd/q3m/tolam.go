@@ -2,8 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/ikarius/q3m"
 	"github.com/spf13/cobra"
@@ -13,39 +11,36 @@ var tolamCmd = &cobra.Command{
 	Use:   "tolam <lat> <lon>",
 	Short: "Convertit des coordonnées WGS84 en Lambert93",
 	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		lat, err := strconv.ParseFloat(args[0], 64)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		lat, err := parseFloatArg("latitude", args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: latitude invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
-		lon, err := strconv.ParseFloat(args[1], 64)
+		lon, err := parseFloatArg("longitude", args[1])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "erreur: longitude invalide: %v\n", err)
-			os.Exit(1)
+			return err
 		}
 
 		E, N := q3m.ToLambert93(lat, lon)
 
 		if jsonOutput {
-			out := struct {
-				E   float64 `json:"e"`
-				N   float64 `json:"n"`
-				Lat float64 `json:"lat"`
-				Lon float64 `json:"lon"`
-			}{
-				E:   E,
-				N:   N,
-				Lat: lat,
-				Lon: lon,
-			}
-			writeJSON(out)
+			writeJSON(tolamResult{E: E, N: N, Lat: lat, Lon: lon})
 		} else {
 			fmt.Printf("%.4f, %.4f\n", E, N)
 		}
+		return nil
 	},
 }
 
+// tolamResult is the JSON shape of a WGS84 to Lambert93 conversion.
+type tolamResult struct {
+	E   float64 `json:"e"`
+	N   float64 `json:"n"`
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
 func init() {
 	rootCmd.AddCommand(tolamCmd)
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <adresse>",
+	Short: "Vérifie une adresse sans la décoder",
+	Long: "Vérifie chaque mot d'une adresse, la plage de son triplet et sa couverture,\n" +
+		"sans calculer de projection. Le code de sortie est 0 pour une adresse\n" +
+		"valide, 8 hors de la couverture terrestre, et celui de decode sinon\n" +
+		"(3 format, 4 mot inconnu, 5 triplet hors plage, 7 espace de noms).",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAddress,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		res := q3m.Validate(args[0])
+
+		if jsonOutput {
+			writeJSON(res)
+		} else {
+			switch {
+			case !res.Valid:
+				fmt.Printf("invalide: %s\n", res.Message)
+			case !res.InCoverage:
+				fmt.Println("valide, hors de la couverture terrestre")
+			default:
+				fmt.Println("valide")
+			}
+			for _, w := range res.Words {
+				known := "connu"
+				if !w.Known {
+					known = "inconnu"
+				}
+				fmt.Printf("  mot %d %q: %s\n", w.Position, w.Word, known)
+			}
+			if res.InvalidCell {
+				fmt.Println("  triplet hors de la plage des cellules")
+			}
+		}
+
+		switch {
+		case !res.Valid:
+			_, code := classifyError(res.Err())
+			return exitCode(code)
+		case !res.InCoverage:
+			return exitCode(exitOutOfCoverage)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
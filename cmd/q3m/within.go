@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ikarius/q3m"
+	"github.com/spf13/cobra"
+)
+
+var (
+	withinZones   string
+	withinNameKey string
+)
+
+var withinCmd = &cobra.Command{
+	Use:   "within <adresse> --zones zones.geojson",
+	Short: "Affiche les zones contenant une adresse",
+	Long: "Affiche, une par ligne, les zones d'un fichier GeoJSON (Polygon ou\n" +
+		"MultiPolygon) qui contiennent le centre de la cellule de l'adresse.\n" +
+		"Les zones sont nommées par la propriété --name-key, à défaut par leur id.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAddress,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if withinZones == "" {
+			return &argError{name: "zones", err: errors.New("--zones requis")}
+		}
+		addr, err := q3m.ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		zones, err := readZones("zones", withinZones, withinNameKey)
+		if err != nil {
+			return err
+		}
+		names, err := q3m.NewGeofence(zones...).Locate(addr)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			writeJSON(withinResult{Address: addr.String(), Zones: append([]string{}, names...)})
+		} else {
+			for _, name := range names {
+				fmt.Println(name)
+			}
+		}
+		return nil
+	},
+}
+
+// withinResult is the JSON shape of the zones containing an address.
+type withinResult struct {
+	Address string   `json:"address"`
+	Zones   []string `json:"zones"`
+}
+
+func init() {
+	withinCmd.Flags().StringVar(&withinZones, "zones", "", "fichier GeoJSON des zones, - pour l'entrée standard")
+	withinCmd.Flags().StringVar(&withinNameKey, "name-key", "name", "propriété portant le nom des zones")
+	rootCmd.AddCommand(withinCmd)
+}
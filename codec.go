@@ -0,0 +1,453 @@
+package q3m
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Codec converts between coordinates and three-word addresses for one
+// dictionary, permutation and grid. The zero value is not usable: build
+// one with NewCodec. A Codec is safe for concurrent use.
+//
+// The package-level Encode and Decode use DefaultCodec, whose addresses are
+// covered by the v1 stability contract. Codecs built with other options
+// produce addresses that only they can decode.
+type Codec struct {
+	dict    *Dictionary
+	levels  [numResolutions]*level // nil where the resolution is unavailable
+	proj    projection
+	private bool
+
+	// region and reg identify the overseas region of a regional codec;
+	// regions holds the regional codecs of DefaultCodec.
+	region  *Region
+	reg     uint8
+	regions []*Codec
+
+	coverage *Coverage
+	policy   CoveragePolicy
+}
+
+// level is the grid and permutation of one resolution.
+type level struct {
+	grid  grid
+	perm  Permutation
+	words int // address length
+}
+
+// newLevel returns the level of g addressed with a dictionary of n words:
+// as few words as can tell all cells apart.
+func newLevel(g grid, perm Permutation, n uint64) *level {
+	words, capacity := 1, n
+	for capacity < g.total() {
+		words++
+		capacity *= n
+	}
+	return &level{grid: g, perm: perm, words: words}
+}
+
+// codecConfig collects the options of NewCodec.
+type codecConfig struct {
+	dict   *Dictionary
+	bounds Bounds
+
+	// perm builds the permutation of [0, domain), the last permutation
+	// option winning; private marks keyed addresses.
+	perm    func(domain uint64) (Permutation, error)
+	private bool
+
+	coverage *Coverage
+	policy   CoveragePolicy
+}
+
+// Option configures a Codec built by NewCodec.
+type Option func(*codecConfig)
+
+// WithDictionary sets the word list. Its size cubed must be at least the
+// number of grid cells.
+func WithDictionary(d *Dictionary) Option {
+	return func(c *codecConfig) { c.dict = d }
+}
+
+// WithKey sets the key of the Feistel permutation that scatters
+// neighbouring cells across the address space.
+func WithKey(key uint64) Option {
+	return func(c *codecConfig) {
+		c.perm = func(domain uint64) (Permutation, error) { return newFeistel(key, domain), nil }
+		c.private = false
+	}
+}
+
+// MinSecretSize is the minimum length in bytes of a WithSecret key.
+const MinSecretSize = 16
+
+// WithSecret keys the Feistel permutation with secret, from which
+// independent round keys are derived. The codec then produces private
+// addresses, written with PrivateMarker, that only a codec holding the same
+// secret can decode.
+//
+// This hides which cell an address designates from anyone lacking the
+// secret, but the Feistel round function is not a vetted cipher: use WithFF1
+// against a determined cryptanalyst.
+func WithSecret(secret []byte) Option {
+	secret = bytes.Clone(secret)
+	return func(c *codecConfig) {
+		c.perm = func(domain uint64) (Permutation, error) {
+			if len(secret) < MinSecretSize {
+				return nil, fmt.Errorf("q3m: secret of %d bytes, need at least %d", len(secret), MinSecretSize)
+			}
+			return newSecretFeistel(secret, domain), nil
+		}
+		c.private = true
+	}
+}
+
+// WithFF1 uses the FF1 format-preserving cipher (NIST SP 800-38G) keyed by
+// an AES key of 16, 24 or 32 bytes as the permutation. Like WithSecret, the
+// codec produces private addresses.
+func WithFF1(key, tweak []byte) Option {
+	return func(c *codecConfig) {
+		c.perm = func(domain uint64) (Permutation, error) { return NewFF1(key, tweak, domain) }
+		c.private = true
+	}
+}
+
+// WithPermutation uses p, whose domain must be the number of grid cells, as
+// the permutation. The codec produces public addresses.
+func WithPermutation(p Permutation) Option {
+	return func(c *codecConfig) {
+		c.perm = func(domain uint64) (Permutation, error) {
+			if p.Domain() != domain {
+				return nil, fmt.Errorf("q3m: permutation domain %d, grid has %d cells", p.Domain(), domain)
+			}
+			return p, nil
+		}
+		c.private = false
+	}
+}
+
+// WithGrid restricts the codec to a grid of 1m cells covering b, in
+// Lambert93 metres. The edges of b must fall on whole metres.
+func WithGrid(b Bounds) Option {
+	return func(c *codecConfig) { c.bounds = b }
+}
+
+// WithCoverage sets the coverage checked by WithCoveragePolicy, by default
+// DefaultCoverage.
+func WithCoverage(cov *Coverage) Option {
+	return func(c *codecConfig) { c.coverage = cov }
+}
+
+// WithCoveragePolicy sets what Encode does with points of the grid outside
+// the coverage. The default, CoverageAllow, encodes them like any other.
+func WithCoveragePolicy(p CoveragePolicy) Option {
+	return func(c *codecConfig) { c.policy = p }
+}
+
+// NewCodec returns a codec configured by opts. Options not given keep the
+// defaults: the embedded dictionary, the v1 Feistel key, the metropolitan
+// grid and no coverage check. Of WithKey, WithSecret, WithFF1 and WithPermutation, the last given
+// applies.
+//
+// Coarser resolutions are available when the grid sides are multiples of
+// their cell size and the permutation can be built for their cell count;
+// WithPermutation only provides the 1m resolution.
+func NewCodec(opts ...Option) (*Codec, error) {
+	cfg := codecConfig{bounds: defaultGrid.bounds}
+	WithKey(feistelKey)(&cfg)
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.dict == nil {
+		cfg.dict = DefaultDictionary()
+	}
+	if cfg.coverage == nil {
+		cfg.coverage = DefaultCoverage()
+	}
+	g, err := newGrid(cfg.bounds)
+	if err != nil {
+		return nil, err
+	}
+	n := uint64(cfg.dict.Len())
+	if n*n*n < g.total() {
+		return nil, fmt.Errorf("q3m: dictionary of %d words cannot address %d cells", n, g.total())
+	}
+	perm, err := cfg.perm(g.total())
+	if err != nil {
+		return nil, err
+	}
+	c := &Codec{dict: cfg.dict, proj: lambert93{}, private: cfg.private, coverage: cfg.coverage, policy: cfg.policy}
+	c.levels[Res1m] = newLevel(g, perm, n)
+	for r := Res10m; r < numResolutions; r++ {
+		cg, ok := g.coarsen(r.Size())
+		if !ok {
+			continue
+		}
+		if perm, err := cfg.perm(cg.total()); err == nil {
+			c.levels[r] = newLevel(cg, perm, n)
+		}
+	}
+	return c, nil
+}
+
+var defaultCodec = sync.OnceValue(func() *Codec {
+	dict := DefaultDictionary()
+	n := uint64(dict.Len())
+	c := &Codec{dict: dict, proj: lambert93{}}
+	c.levels[Res1m] = newLevel(defaultGrid, defaultFeistel, n)
+	for r := Res10m; r < numResolutions; r++ {
+		g := defaultGrids[r]
+		c.levels[r] = newLevel(g, newFeistel(feistelKey, g.total()), n)
+	}
+	for i := range regions {
+		c.regions = append(c.regions, newRegionCodec(i, dict))
+	}
+	return c
+})
+
+// DefaultCodec returns the codec behind the package-level functions.
+func DefaultCodec() *Codec {
+	return defaultCodec()
+}
+
+// Dictionary returns the word list of c.
+func (c *Codec) Dictionary() *Dictionary {
+	return c.dict
+}
+
+// Private reports whether c was keyed with WithSecret or WithFF1.
+func (c *Codec) Private() bool {
+	return c.private
+}
+
+// Bounds returns the Lambert93 extent of the grid of c. The overseas
+// regions of DefaultCodec have their own grids, see Regions.
+func (c *Codec) Bounds() Bounds {
+	return c.levels[Res1m].grid.bounds
+}
+
+// Words returns the number of words of the addresses of resolution r, or 0
+// if c does not provide r.
+func (c *Codec) Words(r Resolution) int {
+	if !r.Valid() || c.levels[r] == nil {
+		return 0
+	}
+	return c.levels[r].words
+}
+
+// Encode converts WGS84 coordinates to the address of their 1m cell.
+// Errors are the same as EncodeAt.
+func (c *Codec) Encode(lat, lon float64) (Address, error) {
+	return c.EncodeAt(lat, lon, Res1m)
+}
+
+// EncodeAt converts WGS84 coordinates to the address of their cell of
+// resolution r. The error is an *OutOfGridError wrapping ErrOutOfGrid, an
+// *OutOfCoverageError wrapping ErrOutOfCoverage under CoverageWarn (along
+// with the address) or CoverageReject, or a plain error if c does not
+// provide r.
+func (c *Codec) EncodeAt(lat, lon float64, r Resolution) (Address, error) {
+	if rc := c.regionAt(lat, lon); rc != nil {
+		return rc.EncodeAt(lat, lon, r)
+	}
+	if c.Words(r) == 0 {
+		return Address{}, fmt.Errorf("q3m: resolution %s not available", r)
+	}
+	e, n := c.proj.forward(lat, lon)
+	idx, ok := c.levels[r].grid.cellIndex(e, n)
+	if !ok {
+		return Address{}, &OutOfGridError{Lat: lat, Lon: lon, E: e, N: n}
+	}
+	err := c.coverageError(lat, lon, e, n)
+	if err != nil && c.policy == CoverageReject {
+		return Address{}, err
+	}
+	return c.address(idx, r), err
+}
+
+// Decode converts an address back to the WGS84 centre of its cell. The
+// address is first normalized as by NormalizeAddress.
+// Errors are *AddressError values wrapping ErrInvalidFormat, ErrUnknownWord,
+// ErrInvalidCell or ErrNamespace.
+func (c *Codec) Decode(address string) (Coordinate, error) {
+	coord, _, err := c.DecodePrecise(address)
+	return coord, err
+}
+
+// EncodePrecise converts WGS84 coordinates to the address of their 1m cell
+// followed, unless p is Precision1m, by the word of their sub-cell of
+// precision p. Errors are the same as Encode, or a plain error if the
+// dictionary of c is too small to number sub-cells.
+func (c *Codec) EncodePrecise(lat, lon float64, p Precision) (Address, error) {
+	if rc := c.regionAt(lat, lon); rc != nil {
+		return rc.EncodePrecise(lat, lon, p)
+	}
+	if !c.precise(p) {
+		return Address{}, fmt.Errorf("q3m: precision %s not available", p)
+	}
+	e, n := c.proj.forward(lat, lon)
+	g := c.levels[Res1m].grid
+	idx, ok := g.cellIndex(e, n)
+	if !ok {
+		return Address{}, &OutOfGridError{Lat: lat, Lon: lon, E: e, N: n}
+	}
+	err := c.coverageError(lat, lon, e, n)
+	if err != nil && c.policy == CoverageReject {
+		return Address{}, err
+	}
+	a := c.address(idx, Res1m)
+	if p != Precision1m {
+		x, y := subOffset(p, e-g.bounds.EMin-float64(idx%g.width), n-g.bounds.NMin-float64(idx/g.width))
+		a.W4 = c.dict.Word(subIndex(p, x, y))
+	}
+	return a, err
+}
+
+// DecodePrecise is Decode, also reporting the precision of the address:
+// Precision1m without a sub-cell word.
+func (c *Codec) DecodePrecise(address string) (Coordinate, Precision, error) {
+	c, err := c.codecFor(address)
+	if err != nil {
+		return Coordinate{}, 0, err
+	}
+	a, cell, err := c.parse(address)
+	if err != nil {
+		return Coordinate{}, 0, err
+	}
+	e, n, p := c.gridCenter(a, cell)
+	lat, lon := c.proj.inverse(e, n)
+	return Coordinate{Lat: lat, Lon: lon}, p, nil
+}
+
+// gridCenter returns the centre, in the grid of c, of the cell of a parsed
+// by c, or of its sub-cell for a four-word address, with its precision.
+func (c *Codec) gridCenter(a Address, cell Cell) (e, n float64, p Precision) {
+	if a.W4 == "" {
+		e, n = c.levels[cell.res].grid.cellCenter(cell.idx)
+		return e, n, Precision1m
+	}
+	i, _ := c.dict.Index(a.W4)
+	p, x, y, _ := subCell(i)
+	g := c.levels[Res1m].grid
+	s := p.Size()
+	e = g.bounds.EMin + float64(cell.idx%g.width) + (float64(x)+0.5)*s
+	n = g.bounds.NMin + float64(cell.idx/g.width) + (float64(y)+0.5)*s
+	return e, n, p
+}
+
+// precise reports whether c provides precision p: sub-cell words are the
+// first subWords words of the dictionary.
+func (c *Codec) precise(p Precision) bool {
+	return p == Precision1m || p.Valid() && c.dict.Len() >= subWords
+}
+
+// ParseAddress normalizes address as by NormalizeAddress, validates it and
+// returns it in canonical form. Errors are the same as Decode.
+func (c *Codec) ParseAddress(address string) (Address, error) {
+	c, err := c.codecFor(address)
+	if err != nil {
+		return Address{}, err
+	}
+	a, _, err := c.parse(address)
+	return a, err
+}
+
+// center returns the WGS84 centre of cell, a cell of the grid of c.
+func (c *Codec) center(cell Cell) Coordinate {
+	lat, lon := c.proj.inverse(c.levels[cell.res].grid.cellCenter(cell.idx))
+	return Coordinate{Lat: lat, Lon: lon}
+}
+
+// address returns the address of the cell idx of resolution r.
+func (c *Codec) address(idx uint64, r Resolution) Address {
+	l := c.levels[r]
+	shuffled := l.perm.Shuffle(idx)
+	n := uint64(c.dict.Len())
+
+	var words [3]string
+	for i := l.words - 1; i >= 0; i-- {
+		words[i] = c.dict.Word(int(shuffled % n))
+		shuffled /= n
+	}
+	return Address{
+		W1: words[0],
+		W2: words[1],
+		W3: words[2],
+
+		Region:     c.regionCode(),
+		Private:    c.private,
+		Resolution: r,
+	}
+}
+
+// parse validates address, whose region prefix must be that of c, and
+// returns it with its cell in the grid of c.
+func (c *Codec) parse(address string) (Address, Cell, error) {
+	body, private := strings.CutPrefix(normalizeAddress(address), PrivateMarker)
+	if private != c.private {
+		return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonNamespace}
+	}
+	region := ""
+	if code, words, ok := strings.Cut(body, RegionMarker); ok {
+		region, body = code, words
+	}
+	if region != c.regionCode() || strings.Contains(body, RegionMarker) {
+		return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+	}
+	r := Res1m
+	if words, marker, ok := strings.Cut(body, ResolutionMarker); ok {
+		var err error
+		if r, err = ParseResolution(marker); err != nil {
+			return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+		}
+		body = words
+	}
+	parts := strings.Split(body, ".")
+	// A 1m address may end with the word of a sub-cell.
+	var sub string
+	if r == Res1m && len(parts) == c.Words(r)+1 && c.precise(Precision1cm) {
+		parts, sub = parts[:len(parts)-1], parts[len(parts)-1]
+	}
+	if len(parts) != c.Words(r) {
+		return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+	}
+	l := c.levels[r]
+
+	n := uint64(c.dict.Len())
+	var shuffled uint64
+	for i, p := range parts {
+		idx, ok := c.dict.Index(p)
+		if !ok {
+			return Address{}, Cell{}, &AddressError{Address: address, Position: i + 1, Token: p, Reason: ReasonUnknownWord}
+		}
+		shuffled = shuffled*n + uint64(idx)
+	}
+
+	// The permutation only produces values below the cell count: the
+	// remaining triplets are never emitted by Encode and would alias
+	// another cell.
+	if shuffled >= l.grid.total() {
+		return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonInvalidCell}
+	}
+
+	a := Address{W1: parts[0], Region: region, Private: private, Resolution: r}
+	if len(parts) > 1 {
+		a.W2 = parts[1]
+	}
+	if len(parts) > 2 {
+		a.W3 = parts[2]
+	}
+	if sub != "" {
+		i, ok := c.dict.Index(sub)
+		if !ok {
+			return Address{}, Cell{}, &AddressError{Address: address, Position: len(parts) + 1, Token: sub, Reason: ReasonUnknownWord}
+		}
+		if _, _, _, ok := subCell(i); !ok {
+			return Address{}, Cell{}, &AddressError{Address: address, Reason: ReasonInvalidCell}
+		}
+		a.W4 = sub
+	}
+	return a, Cell{idx: l.perm.Unshuffle(shuffled), res: r, reg: c.reg}, nil
+}
@@ -0,0 +1,243 @@
+package q3m
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// eiffelBounds is a 100m x 100m grid around the Eiffel Tower.
+var eiffelBounds = Bounds{EMin: 648200, NMin: 6862200, EMax: 648300, NMax: 6862300}
+
+// tinyDictionary returns n made-up words; 22 words address 10648 cells.
+func tinyDictionary(t *testing.T, n int) *Dictionary {
+	t.Helper()
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fmt.Sprintf("mot%02d", i)
+	}
+	d, err := NewDictionary(words)
+	if err != nil {
+		t.Fatalf("NewDictionary: %v", err)
+	}
+	return d
+}
+
+func TestDefaultCodecMatchesPackage(t *testing.T) {
+	c, err := NewCodec()
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	for _, codec := range []*Codec{c, DefaultCodec()} {
+		addr, err := codec.Encode(eiffel.Lat, eiffel.Lon)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		if addr.String() != "province.shootons.retirons" {
+			t.Errorf("Encode(Tour Eiffel) = %s, want province.shootons.retirons", addr)
+		}
+	}
+}
+
+func TestCodecTinyGrid(t *testing.T) {
+	c, err := NewCodec(WithDictionary(tinyDictionary(t, 22)), WithGrid(eiffelBounds))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	if c.Bounds() != eiffelBounds || c.Dictionary().Len() != 22 {
+		t.Errorf("Bounds/Dictionary = %v/%d", c.Bounds(), c.Dictionary().Len())
+	}
+
+	addr, err := c.Encode(eiffel.Lat, eiffel.Lon)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := c.Decode(addr.String())
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", addr, err)
+	}
+	if math.Abs(got.Lat-eiffel.Lat) > 0.00002 || math.Abs(got.Lon-eiffel.Lon) > 0.00002 {
+		t.Errorf("round trip %s -> %v", addr, got)
+	}
+
+	// Every cell gets a distinct address that decodes back to it.
+	seen := make(map[Address]uint64)
+	for idx := range c.levels[Res1m].grid.total() {
+		a := c.address(idx, Res1m)
+		if prev, ok := seen[a]; ok {
+			t.Fatalf("cells %d and %d share %s", prev, idx, a)
+		}
+		seen[a] = idx
+		if _, back, err := c.parse(a.String()); err != nil || back.idx != idx {
+			t.Fatalf("parse(%s) = %d, %v; want %d", a, back.idx, err, idx)
+		}
+	}
+
+	if _, err := c.Encode(48.8530, 2.3499); !errors.Is(err, ErrOutOfGrid) {
+		t.Errorf("Encode outside the grid: err = %v, want ErrOutOfGrid", err)
+	}
+	if _, err := c.Decode("province.shootons.retirons"); !errors.Is(err, ErrUnknownWord) {
+		t.Errorf("Decode of a default address: err = %v, want ErrUnknownWord", err)
+	}
+	if _, err := c.Decode("mot21.mot21.mot21"); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("Decode(mot21.mot21.mot21): err = %v, want ErrInvalidCell", err)
+	}
+}
+
+func TestCodecKey(t *testing.T) {
+	c, err := NewCodec(WithKey(42))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	addr, err := c.Encode(eiffel.Lat, eiffel.Lon)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if addr.String() == "province.shootons.retirons" {
+		t.Error("a different key should give a different address")
+	}
+	got, err := c.Decode(addr.String())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if math.Abs(got.Lat-eiffel.Lat) > 0.00002 || math.Abs(got.Lon-eiffel.Lon) > 0.00002 {
+		t.Errorf("round trip %s -> %v", addr, got)
+	}
+}
+
+func TestNewCodecErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"dictionary too small", []Option{WithDictionary(tinyDictionary(t, 21)), WithGrid(eiffelBounds)}},
+		{"empty grid", []Option{WithGrid(Bounds{EMin: 1000, NMin: 1000, EMax: 1000, NMax: 2000})}},
+		{"fractional grid", []Option{WithGrid(Bounds{EMin: 0.5, NMin: 0, EMax: 10, NMax: 10})}},
+		{"NaN grid", []Option{WithGrid(Bounds{EMin: math.NaN(), NMin: 0, EMax: 10, NMax: 10})}},
+	}
+	for _, tt := range tests {
+		if _, err := NewCodec(tt.opts...); err == nil {
+			t.Errorf("%s: NewCodec succeeded", tt.name)
+		}
+	}
+}
+
+func TestNewDictionaryErrors(t *testing.T) {
+	for _, words := range [][]string{
+		nil,
+		{"un", "deux", "un"},
+		{"un", ""},
+		{"un", "Deux"},
+		{"un", "de.ux"},
+		{"un", "de ux"},
+		{"un", "élève"},
+		{"un", "arc-en-ciel"},
+		{"un", "a:b"},
+		{"un", "a@b"},
+		{"un", "~un"},
+		{"un", "«deux»"},
+	} {
+		if _, err := NewDictionary(words); err == nil {
+			t.Errorf("NewDictionary(%q) succeeded", words)
+		}
+	}
+}
+
+// TestDictionaryRoundTrip checks that the addresses of a custom dictionary
+// decode back to their cell once typed by a user, as NormalizeAddress sees
+// them.
+func TestDictionaryRoundTrip(t *testing.T) {
+	words := strings.Fields("ours chene eleve oeuvre arc ciel mer baie cap col lac " +
+		"pic ile roc val gue pre bois puy mont anse dune")
+	d, err := NewDictionary(words)
+	if err != nil {
+		t.Fatalf("NewDictionary: %v", err)
+	}
+	c, err := NewCodec(WithDictionary(d), WithGrid(eiffelBounds))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	for idx := range c.levels[Res1m].grid.total() {
+		a := c.address(idx, Res1m)
+		typed, _ := NormalizeAddress(strings.ToUpper(strings.ReplaceAll(a.String(), ".", " ")))
+		if _, back, err := c.parse(typed); err != nil || back.idx != idx {
+			t.Fatalf("parse(%s) = %d, %v; want %d", typed, back.idx, err, idx)
+		}
+	}
+}
+
+func TestFeistelSmallDomains(t *testing.T) {
+	for _, domain := range []uint64{1, 2, 3, 1000, 1 << 12} {
+		f := newFeistel(7, domain)
+		seen := make(map[uint64]bool)
+		for i := range domain {
+			s := f.Shuffle(i)
+			if s >= domain || seen[s] {
+				t.Fatalf("domain %d: shuffle(%d) = %d is out of range or repeated", domain, i, s)
+			}
+			seen[s] = true
+			if f.Unshuffle(s) != i {
+				t.Fatalf("domain %d: unshuffle(shuffle(%d)) != %d", domain, i, i)
+			}
+		}
+	}
+}
+
+func TestCodecSecret(t *testing.T) {
+	secret := []byte("nid de gypaète, ne pas diffuser")
+	c, err := NewCodec(WithSecret(secret))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	if !c.Private() || DefaultCodec().Private() {
+		t.Error("only the keyed codec should be private")
+	}
+	addr, err := c.Encode(eiffel.Lat, eiffel.Lon)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !addr.Private || !strings.HasPrefix(addr.String(), PrivateMarker) {
+		t.Errorf("Encode = %q, want a private address", addr)
+	}
+	if addr.W1 == "province" && addr.W2 == "shootons" {
+		t.Errorf("private address %s reuses the public words", addr)
+	}
+
+	got, err := c.Decode(addr.String())
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", addr, err)
+	}
+	if math.Abs(got.Lat-eiffel.Lat) > 0.00002 || math.Abs(got.Lon-eiffel.Lon) > 0.00002 {
+		t.Errorf("round trip %s -> %v", addr, got)
+	}
+
+	// The namespaces do not mix, whichever way round.
+	if _, err := Decode(addr.String()); !errors.Is(err, ErrNamespace) {
+		t.Errorf("public Decode(%s): err = %v, want ErrNamespace", addr, err)
+	}
+	if _, err := c.Decode("province.shootons.retirons"); !errors.Is(err, ErrNamespace) {
+		t.Errorf("private Decode of a public address: err = %v, want ErrNamespace", err)
+	}
+
+	// Another secret reads the same words as another cell, if any.
+	other, err := NewCodec(WithSecret([]byte("une autre clé secrète de 32 o..")))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	if got2, err := other.Decode(addr.String()); err == nil && got2 == got {
+		t.Errorf("another secret decodes %s to the same place", addr)
+	}
+
+	// The caller may reuse its buffer.
+	c2, _ := NewCodec(WithSecret(secret))
+	clear(secret)
+	if again, _ := c2.Encode(eiffel.Lat, eiffel.Lon); again != addr {
+		t.Errorf("Encode after clearing the secret buffer = %s, want %s", again, addr)
+	}
+
+	if _, err := NewCodec(WithSecret([]byte("court"))); err == nil {
+		t.Error("NewCodec accepted a 5-byte secret")
+	}
+}
@@ -0,0 +1,188 @@
+package q3m
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed coverage_fr.txt
+var coverageRaw string
+
+// DefaultCoverageBuffer is the buffer of DefaultCoverage, in metres.
+const DefaultCoverageBuffer = 2000.0
+
+// Coverage is the area where addresses are meaningful: the land of
+// metropolitan France and Corsica, from an embedded simplified outline,
+// grown by a buffer that keeps the coast and nearshore waters covered. The
+// Lambert93 grid also spans the open sea and neighbouring countries.
+type Coverage struct {
+	rings  []coverageRing
+	buffer float64
+}
+
+// coverageRing is one polygon of the outline, in Lambert93 metres.
+type coverageRing struct {
+	pts    [][2]float64
+	bounds Bounds // grown by the buffer
+}
+
+var coverageRings = sync.OnceValue(func() []coverageRing {
+	var rings []coverageRing
+	var pts [][2]float64
+	flush := func() {
+		if len(pts) > 0 {
+			rings = append(rings, coverageRing{pts: pts})
+			pts = nil
+		}
+	}
+	for line := range strings.Lines(coverageRaw) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 2 {
+			panic(fmt.Sprintf("q3m: bad coverage vertex %q", line))
+		}
+		lat, err1 := strconv.ParseFloat(f[0], 64)
+		lon, err2 := strconv.ParseFloat(f[1], 64)
+		if err1 != nil || err2 != nil {
+			panic(fmt.Sprintf("q3m: bad coverage vertex %q", line))
+		}
+		e, n := ToLambert93(lat, lon)
+		pts = append(pts, [2]float64{e, n})
+	}
+	flush()
+	return rings
+})
+
+// NewCoverage returns the coverage of metropolitan France and Corsica with
+// a buffer of the given width in metres around the outline, at sea as well
+// as across land borders.
+func NewCoverage(buffer float64) *Coverage {
+	c := &Coverage{buffer: max(buffer, 0)}
+	for _, r := range coverageRings() {
+		b := Bounds{EMin: math.Inf(1), NMin: math.Inf(1), EMax: math.Inf(-1), NMax: math.Inf(-1)}
+		for _, p := range r.pts {
+			b.EMin, b.EMax = min(b.EMin, p[0]), max(b.EMax, p[0])
+			b.NMin, b.NMax = min(b.NMin, p[1]), max(b.NMax, p[1])
+		}
+		r.bounds = Bounds{EMin: b.EMin - c.buffer, NMin: b.NMin - c.buffer, EMax: b.EMax + c.buffer, NMax: b.NMax + c.buffer}
+		c.rings = append(c.rings, r)
+	}
+	return c
+}
+
+var defaultCoverage = sync.OnceValue(func() *Coverage {
+	return NewCoverage(DefaultCoverageBuffer)
+})
+
+// DefaultCoverage returns the coverage with DefaultCoverageBuffer.
+func DefaultCoverage() *Coverage {
+	return defaultCoverage()
+}
+
+// InCoverage reports whether the WGS84 point (lat, lon) lies in the default
+// coverage.
+func InCoverage(lat, lon float64) bool {
+	return DefaultCoverage().Contains(lat, lon)
+}
+
+// Buffer returns the width of the buffer around the outline, in metres.
+func (c *Coverage) Buffer() float64 {
+	return c.buffer
+}
+
+// Contains reports whether the WGS84 point (lat, lon) lies in c.
+func (c *Coverage) Contains(lat, lon float64) bool {
+	return c.ContainsLambert(ToLambert93(lat, lon))
+}
+
+// ContainsLambert reports whether the Lambert93 point (E, N) lies in c.
+func (c *Coverage) ContainsLambert(E, N float64) bool {
+	for _, r := range c.rings {
+		b := r.bounds
+		if E < b.EMin || E > b.EMax || N < b.NMin || N > b.NMax {
+			continue
+		}
+		if r.contains(E, N) || r.distance(E, N) <= c.buffer {
+			return true
+		}
+	}
+	return false
+}
+
+// Area returns the area enclosed by the outline, without the buffer, in
+// square metres. Lambert93 is conformal, not equal-area: the figure is
+// within a few tenths of a percent.
+func (c *Coverage) Area() float64 {
+	var total float64
+	for _, r := range c.rings {
+		var a float64
+		for i, p := range r.pts {
+			q := r.pts[(i+1)%len(r.pts)]
+			a += p[0]*q[1] - q[0]*p[1]
+		}
+		total += math.Abs(a) / 2
+	}
+	return total
+}
+
+// contains reports whether (E, N) is inside r, by the even-odd rule.
+func (r coverageRing) contains(E, N float64) bool {
+	in := false
+	for i, p := range r.pts {
+		q := r.pts[(i+1)%len(r.pts)]
+		if (p[1] > N) != (q[1] > N) && E < p[0]+(N-p[1])*(q[0]-p[0])/(q[1]-p[1]) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// distance returns the distance from (E, N) to the outline of r.
+func (r coverageRing) distance(E, N float64) float64 {
+	d := math.Inf(1)
+	for i, p := range r.pts {
+		q := r.pts[(i+1)%len(r.pts)]
+		dx, dy := q[0]-p[0], q[1]-p[1]
+		t := 0.0
+		if l := dx*dx + dy*dy; l > 0 {
+			t = min(max(((E-p[0])*dx+(N-p[1])*dy)/l, 0), 1)
+		}
+		d = min(d, math.Hypot(E-p[0]-t*dx, N-p[1]-t*dy))
+	}
+	return d
+}
+
+// CoveragePolicy is what Encode does with a point of the grid outside the
+// coverage of its codec.
+type CoveragePolicy uint8
+
+const (
+	// CoverageAllow encodes every point of the grid, as in v1.
+	CoverageAllow CoveragePolicy = iota
+	// CoverageWarn encodes the point but returns the address together with
+	// an *OutOfCoverageError.
+	CoverageWarn
+	// CoverageReject returns an *OutOfCoverageError and no address.
+	CoverageReject
+)
+
+// coverageError applies the coverage policy of c to the point (lat, lon),
+// projected to (E, N): nil if the point may be encoded silently, an
+// *OutOfCoverageError otherwise.
+func (c *Codec) coverageError(lat, lon, E, N float64) error {
+	if c.policy == CoverageAllow || c.coverage.ContainsLambert(E, N) {
+		return nil
+	}
+	return &OutOfCoverageError{Lat: lat, Lon: lon, E: E, N: N}
+}
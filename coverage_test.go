@@ -0,0 +1,109 @@
+package q3m
+
+import (
+	"errors"
+	"testing"
+)
+
+var coveragePoints = []struct {
+	name     string
+	lat, lon float64
+	in       bool
+}{
+	{"Paris", 48.8566, 2.3522, true},
+	{"Brest", 48.39, -4.49, true},
+	{"Marseille", 43.2965, 5.3698, true},
+	{"Ajaccio", 41.9192, 8.7386, true},
+	{"Bonifacio", 41.3874, 9.1595, true},
+	{"Strasbourg", 48.5734, 7.7521, true},
+	{"Dunkerque", 51.0344, 2.3768, true},
+	{"Hendaye", 43.3586, -1.7744, true},
+	{"Cerbère", 42.442, 3.167, true},
+	{"Menton", 43.7747, 7.4975, true},
+	{"Cap de la Hague", 49.7248, -1.9373, true},
+	{"Ouessant", 48.4569, -5.0956, true},
+	{"Belle-Île", 47.3476, -3.1543, true},
+	{"Porquerolles", 43.00, 6.20, true},
+	{"Bern", 46.948, 7.4474, false},
+	{"Genève", 46.2044, 6.1432, false},
+	{"Lausanne", 46.5197, 6.6323, false},
+	{"Bâle", 47.5596, 7.5886, false},
+	{"Fribourg-en-Brisgau", 47.999, 7.842, false},
+	{"Bruxelles", 50.85, 4.35, false},
+	{"Andorre", 42.5063, 1.5218, false},
+	{"Turin", 45.07, 7.686, false},
+	{"Jersey", 49.21, -2.13, false},
+	{"golfe de Gascogne", 45.5, -3.0, false},
+	{"golfe du Lion", 42.5, 4.5, false},
+	{"Manche", 50.0, -0.5, false},
+}
+
+func TestInCoverage(t *testing.T) {
+	for _, p := range coveragePoints {
+		if got := InCoverage(p.lat, p.lon); got != p.in {
+			t.Errorf("InCoverage(%s) = %v, want %v", p.name, got, p.in)
+		}
+	}
+}
+
+func TestCoverageBuffer(t *testing.T) {
+	// Some 5km off the Pointe Saint-Mathieu.
+	lat, lon := 48.31, -4.84
+	if NewCoverage(0).Contains(lat, lon) {
+		t.Error("point at sea covered without buffer")
+	}
+	if !NewCoverage(10_000).Contains(lat, lon) {
+		t.Error("point at sea not covered by a 10km buffer")
+	}
+	if b := DefaultCoverage().Buffer(); b != DefaultCoverageBuffer {
+		t.Errorf("Buffer() = %v", b)
+	}
+}
+
+func TestCoverageArea(t *testing.T) {
+	// Metropolitan France and Corsica cover about 550,000 km².
+	if a := DefaultCoverage().Area() / 1e6; a < 530_000 || a > 580_000 {
+		t.Errorf("Area() = %.0f km²", a)
+	}
+}
+
+func TestCoveragePolicy(t *testing.T) {
+	const lat, lon = 46.948, 7.4474 // Bern
+
+	if _, err := Encode(lat, lon); err != nil {
+		t.Errorf("default codec: %v", err)
+	}
+
+	warn, err := NewCodec(WithCoveragePolicy(CoverageWarn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := warn.Encode(lat, lon)
+	if !errors.Is(err, ErrOutOfCoverage) || addr.W1 == "" {
+		t.Errorf("CoverageWarn: %v, %v", addr, err)
+	}
+	if want, _ := Encode(lat, lon); addr != want {
+		t.Errorf("CoverageWarn address %s, want %s", addr, want)
+	}
+
+	reject, err := NewCodec(WithCoveragePolicy(CoverageReject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err = reject.EncodeAt(lat, lon, Res1km)
+	var ce *OutOfCoverageError
+	if !errors.As(err, &ce) || addr != (Address{}) || ce.Lat != lat {
+		t.Errorf("CoverageReject: %v, %v", addr, err)
+	}
+	if _, err := reject.EncodePrecise(eiffel.Lat, eiffel.Lon, Precision1cm); err != nil {
+		t.Errorf("CoverageReject in coverage: %v", err)
+	}
+
+	wide, err := NewCodec(WithCoveragePolicy(CoverageReject), WithCoverage(NewCoverage(200_000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wide.Encode(lat, lon); err != nil {
+		t.Errorf("CoverageReject with a 200km buffer: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package q3m
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors returned (wrapped) by Encode and Decode. Use errors.Is to
+// test for a category and errors.As to retrieve the structured details.
+var (
+	// ErrInvalidFormat reports an address that is not of the form w1.w2.w3.
+	ErrInvalidFormat = errors.New("q3m: invalid address format")
+	// ErrUnknownWord reports an address word missing from the dictionary.
+	ErrUnknownWord = errors.New("q3m: unknown word")
+	// ErrInvalidCell reports a well-formed triplet that maps outside the grid.
+	ErrInvalidCell = errors.New("q3m: invalid cell index")
+	// ErrOutOfGrid reports coordinates outside the Lambert93 grid.
+	ErrOutOfGrid = errors.New("q3m: outside the Lambert93 grid")
+	// ErrOutOfCoverage reports coordinates of the grid outside the land
+	// coverage, at sea or abroad.
+	ErrOutOfCoverage = errors.New("q3m: outside the land coverage")
+	// ErrNamespace reports a private address given to a public codec, or
+	// the reverse.
+	ErrNamespace = errors.New("q3m: address from another namespace")
+)
+
+// Reason identifies why an address was rejected.
+type Reason string
+
+const (
+	ReasonInvalidFormat Reason = "invalid_format"
+	ReasonUnknownWord   Reason = "unknown_word"
+	ReasonInvalidCell   Reason = "invalid_cell"
+	ReasonNamespace     Reason = "wrong_namespace"
+)
+
+// AddressError describes an address rejected by Decode.
+type AddressError struct {
+	Address  string // the address as given to Decode
+	Position int    // 1-based position of the offending word, 0 for the whole address
+	Token    string // the offending word, empty for the whole address
+	Reason   Reason
+}
+
+func (e *AddressError) Error() string {
+	switch e.Reason {
+	case ReasonUnknownWord:
+		return fmt.Sprintf("q3m: unknown word %q (position %d)", e.Token, e.Position)
+	case ReasonInvalidCell:
+		return fmt.Sprintf("q3m: address %q maps to invalid cell index", e.Address)
+	case ReasonNamespace:
+		if strings.HasPrefix(strings.TrimSpace(e.Address), PrivateMarker) {
+			return fmt.Sprintf("q3m: private address %q requires its secret key", e.Address)
+		}
+		return fmt.Sprintf("q3m: address %q is not private (expected %sw1.w2.w3)", e.Address, PrivateMarker)
+	default:
+		return fmt.Sprintf("q3m: invalid address format %q (expected w1.w2.w3, w1.w2.w3.w4 or w1.w2.w3@resolution)", e.Address)
+	}
+}
+
+// Unwrap returns the sentinel error matching e.Reason.
+func (e *AddressError) Unwrap() error {
+	switch e.Reason {
+	case ReasonUnknownWord:
+		return ErrUnknownWord
+	case ReasonInvalidCell:
+		return ErrInvalidCell
+	case ReasonNamespace:
+		return ErrNamespace
+	default:
+		return ErrInvalidFormat
+	}
+}
+
+// OutOfGridError describes coordinates rejected by Encode.
+type OutOfGridError struct {
+	Lat, Lon float64 // WGS84 input
+	E, N     float64 // projected Lambert93 position
+}
+
+func (e *OutOfGridError) Error() string {
+	return fmt.Sprintf("q3m: coordinates (%f, %f) are outside the Lambert93 grid (E=%.1f, N=%.1f)",
+		e.Lat, e.Lon, e.E, e.N)
+}
+
+// Unwrap returns ErrOutOfGrid.
+func (e *OutOfGridError) Unwrap() error {
+	return ErrOutOfGrid
+}
+
+// OutOfCoverageError describes coordinates of the grid outside the coverage
+// of a codec with CoverageWarn or CoverageReject.
+type OutOfCoverageError struct {
+	Lat, Lon float64 // WGS84 input
+	E, N     float64 // projected Lambert93 position
+}
+
+func (e *OutOfCoverageError) Error() string {
+	return fmt.Sprintf("q3m: coordinates (%f, %f) are outside the land coverage (E=%.1f, N=%.1f)",
+		e.Lat, e.Lon, e.E, e.N)
+}
+
+// Unwrap returns ErrOutOfCoverage.
+func (e *OutOfCoverageError) Unwrap() error {
+	return ErrOutOfCoverage
+}
@@ -0,0 +1,81 @@
+package q3m
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeErrorInvalidFormat(t *testing.T) {
+	_, err := Decode("one.two")
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Decode(one.two) error = %v, want ErrInvalidFormat", err)
+	}
+	var ae *AddressError
+	if !errors.As(err, &ae) {
+		t.Fatalf("error %T is not *AddressError", err)
+	}
+	if ae.Reason != ReasonInvalidFormat || ae.Position != 0 || ae.Address != "one.two" {
+		t.Errorf("AddressError = %+v", ae)
+	}
+}
+
+func TestDecodeErrorUnknownWord(t *testing.T) {
+	addr, err := Encode(48.8584, 2.2945)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	_, err = Decode(addr.W1 + ".xyzzy." + addr.W3)
+	if !errors.Is(err, ErrUnknownWord) {
+		t.Fatalf("error = %v, want ErrUnknownWord", err)
+	}
+	var ae *AddressError
+	if !errors.As(err, &ae) {
+		t.Fatalf("error %T is not *AddressError", err)
+	}
+	if ae.Position != 2 || ae.Token != "xyzzy" || ae.Reason != ReasonUnknownWord {
+		t.Errorf("AddressError = %+v, want position 2, token xyzzy", ae)
+	}
+}
+
+func TestDecodeErrorInvalidCell(t *testing.T) {
+	// Any triplet whose base-10800 value is >= TotalCells is never produced
+	// by Encode.
+	last := WordAt(DictSize - 1)
+	_, err := Decode(last + "." + last + "." + last)
+	if !errors.Is(err, ErrInvalidCell) {
+		t.Fatalf("error = %v, want ErrInvalidCell", err)
+	}
+	var ae *AddressError
+	if !errors.As(err, &ae) || ae.Reason != ReasonInvalidCell {
+		t.Errorf("error = %#v, want *AddressError with ReasonInvalidCell", err)
+	}
+}
+
+func TestEncodeErrorOutOfGrid(t *testing.T) {
+	_, err := Encode(0, 0)
+	if !errors.Is(err, ErrOutOfGrid) {
+		t.Fatalf("error = %v, want ErrOutOfGrid", err)
+	}
+	var oe *OutOfGridError
+	if !errors.As(err, &oe) {
+		t.Fatalf("error %T is not *OutOfGridError", err)
+	}
+	if oe.Lat != 0 || oe.Lon != 0 {
+		t.Errorf("OutOfGridError lat/lon = (%f, %f), want (0, 0)", oe.Lat, oe.Lon)
+	}
+	wantE, wantN := ToLambert93(0, 0)
+	if oe.E != wantE || oe.N != wantN {
+		t.Errorf("OutOfGridError E/N = (%f, %f), want (%f, %f)", oe.E, oe.N, wantE, wantN)
+	}
+}
+
+func TestErrorCategoriesDistinct(t *testing.T) {
+	sentinels := []error{ErrInvalidFormat, ErrUnknownWord, ErrInvalidCell, ErrOutOfGrid, ErrNamespace, ErrOutOfCoverage}
+	for i, a := range sentinels {
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("%v should not match %v", a, b)
+			}
+		}
+	}
+}
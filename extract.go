@@ -0,0 +1,188 @@
+package q3m
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match is an address found in free text by FindAddresses.
+type Match struct {
+	Start int `json:"start"` // byte offset of the address in the text
+	End   int `json:"end"`   // byte offset just past the address
+
+	Address    Address    `json:"address"`
+	Coordinate Coordinate `json:"coordinate"` // centre of its cell
+}
+
+// FindAddresses finds the addresses of DefaultCodec in text (see
+// Codec.FindAddresses).
+func FindAddresses(text string) []Match {
+	return defaultCodec().FindAddresses(text)
+}
+
+// FindAddresses returns the addresses of c written in text, such as "je
+// suis au province.shootons.retirons près du portail", in order. An
+// address is a run of dot-separated words that decodes, with its region
+// prefix, private marker or resolution if any, and may follow "///"; case
+// and accents are ignored as in Decode. Words separated by spaces are not
+// considered: in running French text nearly any three words would make a
+// valid address.
+//
+// Each run of dotted words is read in one pass, three words at a time, so
+// that words attached before an address ("Voir.", "Adresse:", "mail@") or
+// run on after it ("retirons.Puis") are left out of the match, as is a
+// final dot ending a sentence. A fourth word is read as a sub-cell only
+// when it ends the run and is not capitalized after a lowercase word.
+func (c *Codec) FindAddresses(text string) []Match {
+	var matches []Match
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isAddressRune(r) {
+			i += size
+			continue
+		}
+		j := i
+		for j < len(text) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if !isAddressRune(r) {
+				break
+			}
+			j += size
+		}
+		matches = c.matchSpan(matches, text, i, j)
+		i = j
+	}
+	return matches
+}
+
+// isAddressRune reports whether r may be part of an address written in
+// text.
+func isAddressRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) ||
+		r == '.' || r == '/' || isAddressMarker(r)
+}
+
+// matchSpan appends to matches the addresses in text[start:end], a run of
+// address runes. Each window of three words is tried once, from the first
+// word on, so the cost is linear in the length of the run.
+func (c *Codec) matchSpan(matches []Match, text string, start, end int) []Match {
+	// Only what follows the last slash may be an address, introduced by
+	// "///" or ending a URL path.
+	prefixed := false
+	if k := strings.LastIndexByte(text[start:end], '/'); k >= 0 {
+		prefixed = k >= 2 && text[start+k-2:start+k+1] == "///"
+		start += k + 1
+	}
+	end = start + len(strings.TrimRight(text[start:end], ".:~@"))
+
+	// words holds the byte offset in text of each dot-separated word.
+	words := []int{start}
+	for i := start; i < end; i++ {
+		if text[i] == '.' {
+			words = append(words, i+1)
+		}
+	}
+	wordEnd := func(i int) int {
+		if i+1 < len(words) {
+			return words[i+1] - 1
+		}
+		return end
+	}
+
+	for i := 0; i+3 <= len(words); i++ {
+		m, ok := c.matchWords(text, words[i], wordEnd(i), wordEnd(i+2))
+		if !ok {
+			continue
+		}
+		if i+4 == len(words) {
+			third, _ := utf8.DecodeRuneInString(text[words[i+2]:])
+			fourth, _ := utf8.DecodeRuneInString(text[words[i+3]:])
+			if !unicode.IsUpper(fourth) || unicode.IsUpper(third) {
+				if m4, ok := c.matchAt(text, m.Start, end); ok {
+					m = m4
+				}
+			}
+		}
+		if prefixed && m.Start == start {
+			m.Start -= 3
+		}
+		matches = append(matches, m)
+		i += 2
+	}
+	return matches
+}
+
+// matchWords returns the address of text[first:last], whose first word
+// ends at firstEnd. A word attached to the first one by a colon or an at
+// sign ("Adresse:", "mail@") is dropped, unless it reads as a region
+// prefix.
+func (c *Codec) matchWords(text string, first, firstEnd, last int) (Match, bool) {
+	w := text[first:firstEnd]
+	k := strings.LastIndexAny(w, ":@")
+	if k < 0 {
+		return c.matchAt(text, first, last)
+	}
+	if w[k] == ':' {
+		j := strings.LastIndexAny(w[:k], ":@")
+		if m, ok := c.matchAt(text, first+j+1, last); ok {
+			return m, true
+		}
+	}
+	return c.matchAt(text, first+k+1, last)
+}
+
+// matchAt returns text[start:end] as a match if it decodes.
+func (c *Codec) matchAt(text string, start, end int) (Match, bool) {
+	s := text[start:end]
+	a, err := c.ParseAddress(s)
+	if err != nil {
+		return Match{}, false
+	}
+	coord, _, _ := c.DecodePrecise(s)
+	return Match{Start: start, End: end, Address: a, Coordinate: coord}, true
+}
+
+// LinkFormat is the markup of the links written by Linkify.
+type LinkFormat int
+
+const (
+	LinkMarkdown LinkFormat = iota // [address](url)
+	LinkHTML                       // <a href="url">address</a>, the rest of the text escaped
+)
+
+// GeoURI returns the RFC 5870 "geo:" URI of the centre of the cell of m,
+// the default link of Linkify.
+func GeoURI(m Match) string {
+	return fmt.Sprintf("geo:%.7f,%.7f", m.Coordinate.Lat, m.Coordinate.Lon)
+}
+
+// Linkify rewrites the addresses of DefaultCodec found in text into links
+// to url(m), or to GeoURI(m) if url is nil. In LinkHTML format, the rest of
+// text is HTML-escaped so that the result can be inserted as is.
+func Linkify(text string, format LinkFormat, url func(Match) string) string {
+	if url == nil {
+		url = GeoURI
+	}
+	esc := func(s string) string { return s }
+	if format == LinkHTML {
+		esc = html.EscapeString
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range FindAddresses(text) {
+		b.WriteString(esc(text[last:m.Start]))
+		label := text[m.Start:m.End]
+		switch format {
+		case LinkHTML:
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(url(m)), html.EscapeString(label))
+		default:
+			fmt.Fprintf(&b, "[%s](%s)", label, url(m))
+		}
+		last = m.End
+	}
+	b.WriteString(esc(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package q3m
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindAddresses(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string // matched text
+	}{
+		{"je suis au province.shootons.retirons près du portail", []string{"province.shootons.retirons"}},
+		{"Rendez-vous à ///Province.Shootons.Retirons.", []string{"///Province.Shootons.Retirons"}},
+		{"(province.shootons.retirons) puis re:abjurer.ramassis.rossant !", []string{"province.shootons.retirons", "re:abjurer.ramassis.rossant"}},
+		{"c'est province.shootons.retirons.Puis on part", []string{"province.shootons.retirons"}},
+		{"voir https://q3m.example/province.shootons.retirons", []string{"province.shootons.retirons"}},
+		{"Adresse:province.shootons.retirons", []string{"province.shootons.retirons"}},
+		{"voir:province.shootons.retirons, et voir:re:abjurer.ramassis.rossant", []string{"province.shootons.retirons", "re:abjurer.ramassis.rossant"}},
+		{"mail@province.shootons.retirons", []string{"province.shootons.retirons"}},
+		{"province.shootons.retirons.Maison", []string{"province.shootons.retirons"}},
+		{"Voir province.shootons.retirons.Ensuite, on part", []string{"province.shootons.retirons"}},
+		{"province.shootons.retirons.triage", []string{"province.shootons.retirons.triage"}},
+		{"province.shootons.retirons.triage.Ensuite", []string{"province.shootons.retirons"}},
+		{"Voir.province.shootons.retirons", []string{"province.shootons.retirons"}},
+		{"fr.province.shootons.retirons et fr.re:abjurer.ramassis.rossant", []string{"province.shootons.retirons", "re:abjurer.ramassis.rossant"}},
+		{"province.shootons.retirons.province.shootons.retirons", []string{"province.shootons.retirons", "province.shootons.retirons"}},
+		{"province shootons retirons", nil},
+		{"écrire à jean.dupont@example.fr, ou provinxe.shootons.retirons", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range FindAddresses(tt.text) {
+			got = append(got, tt.text[m.Start:m.End])
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("FindAddresses(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	m := FindAddresses("au province.shootons.retirons.")
+	if len(m) != 1 || m[0].Start != 3 || m[0].Address.String() != "province.shootons.retirons" || m[0].Coordinate.Lat < 48.85 {
+		t.Errorf("match = %+v", m)
+	}
+}
+
+func TestLinkify(t *testing.T) {
+	text := "RDV <ici> province.shootons.retirons."
+	if got, want := Linkify(text, LinkMarkdown, nil), "RDV <ici> [province.shootons.retirons](geo:48.8583984,2.2945027)."; got != want {
+		t.Errorf("Linkify(markdown) = %q, want %q", got, want)
+	}
+	url := func(m Match) string { return "https://example.org/?a=" + m.Address.String() + "&z=19" }
+	if got, want := Linkify(text, LinkHTML, url), `RDV &lt;ici&gt; <a href="https://example.org/?a=province.shootons.retirons&amp;z=19">province.shootons.retirons</a>.`; got != want {
+		t.Errorf("Linkify(html) = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkFindAddresses(b *testing.B) {
+	// A long run of dotted words that never decodes.
+	text := strings.Repeat("abcd.", 16000)
+	for i := 0; i < b.N; i++ {
+		FindAddresses(text)
+	}
+}
@@ -0,0 +1,213 @@
+package q3m
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// ff1Rounds is the number of Feistel rounds fixed by SP 800-38G.
+const ff1Rounds = 10
+
+// ff1MinDomain is the smallest radix^n allowed by SP 800-38G.
+const ff1MinDomain = 1_000_000
+
+// ff1Cipher is the FF1 format-preserving cipher of NIST SP 800-38G over
+// numeral strings in the given radix.
+type ff1Cipher struct {
+	block cipher.Block
+	tweak []byte
+	radix uint32
+}
+
+func newFF1Cipher(key, tweak []byte, radix uint32) (*ff1Cipher, error) {
+	if radix < 2 || radix > 1<<16 {
+		return nil, fmt.Errorf("q3m: FF1 radix %d out of [2, 65536]", radix)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("q3m: FF1 key: %w", err)
+	}
+	return &ff1Cipher{block: block, tweak: bytes.Clone(tweak), radix: radix}, nil
+}
+
+// encrypt enciphers the numeral string x (Algorithm 7).
+func (f *ff1Cipher) encrypt(x []uint16) []uint16 {
+	return f.crypt(x, false)
+}
+
+// decrypt inverts encrypt (Algorithm 8).
+func (f *ff1Cipher) decrypt(x []uint16) []uint16 {
+	return f.crypt(x, true)
+}
+
+func (f *ff1Cipher) crypt(x []uint16, decrypt bool) []uint16 {
+	n := len(x)
+	u, v := n/2, n-n/2
+	radix := big.NewInt(int64(f.radix))
+	a := f.num(x[:u])
+	b := f.num(x[u:])
+
+	// b bytes hold NUM(B); d bytes of PRF output feed each round.
+	rv := new(big.Int).Exp(radix, big.NewInt(int64(v)), nil)
+	nb := (new(big.Int).Sub(rv, big.NewInt(1)).BitLen() + 7) / 8
+	d := 4*((nb+3)/4) + 4
+	modU := new(big.Int).Exp(radix, big.NewInt(int64(u)), nil)
+	modV := rv
+
+	t := len(f.tweak)
+	p := []byte{1, 2, 1, byte(f.radix >> 16), byte(f.radix >> 8), byte(f.radix), 10, byte(u)}
+	p = binary.BigEndian.AppendUint32(p, uint32(n))
+	p = binary.BigEndian.AppendUint32(p, uint32(t))
+
+	pad := (16 - (t+nb+1)%16) % 16
+	q := make([]byte, t+pad+1+nb)
+	copy(q, f.tweak)
+	y := new(big.Int)
+	for step := range ff1Rounds {
+		i := step
+		if decrypt {
+			i = ff1Rounds - 1 - step
+		}
+		// The round input is B when enciphering, A when deciphering.
+		in := b
+		if decrypt {
+			in = a
+		}
+		q[t+pad] = byte(i)
+		clear(q[t+pad+1:])
+		in.FillBytes(q[t+pad+1:])
+
+		y.SetBytes(f.prf(p, q, d))
+		m := modU
+		if i%2 == 1 {
+			m = modV
+		}
+		if decrypt {
+			c := new(big.Int).Sub(b, y)
+			c.Mod(c, m)
+			b, a = a, c
+		} else {
+			c := new(big.Int).Add(a, y)
+			c.Mod(c, m)
+			a, b = b, c
+		}
+	}
+	out := make([]uint16, n)
+	f.str(a, out[:u])
+	f.str(b, out[u:])
+	return out
+}
+
+// prf returns the first d bytes of S: the CBC-MAC R of p||q, followed by
+// the encryptions of R xor [j]^16.
+func (f *ff1Cipher) prf(p, q []byte, d int) []byte {
+	var r [16]byte
+	for _, msg := range [][]byte{p, q} {
+		for i := 0; i < len(msg); i += 16 {
+			subtle.XORBytes(r[:], r[:], msg[i:i+16])
+			f.block.Encrypt(r[:], r[:])
+		}
+	}
+	s := append(make([]byte, 0, d+15), r[:]...)
+	for j := uint64(1); len(s) < d; j++ {
+		var blk [16]byte
+		binary.BigEndian.PutUint64(blk[8:], j)
+		subtle.XORBytes(blk[:], blk[:], r[:])
+		f.block.Encrypt(blk[:], blk[:])
+		s = append(s, blk[:]...)
+	}
+	return s[:d]
+}
+
+// num returns the value of the numeral string x, most significant first.
+func (f *ff1Cipher) num(x []uint16) *big.Int {
+	v := new(big.Int)
+	radix := big.NewInt(int64(f.radix))
+	for _, d := range x {
+		v.Mul(v, radix)
+		v.Add(v, big.NewInt(int64(d)))
+	}
+	return v
+}
+
+// str writes v as len(out) numerals into out.
+func (f *ff1Cipher) str(v *big.Int, out []uint16) {
+	v = new(big.Int).Set(v)
+	radix := big.NewInt(int64(f.radix))
+	digit := new(big.Int)
+	for i := len(out) - 1; i >= 0; i-- {
+		v.DivMod(v, radix, digit)
+		out[i] = uint16(digit.Uint64())
+	}
+}
+
+// FF1 is a Permutation of [0, domain) built on the FF1 cipher of NIST
+// SP 800-38G with AES: indices are enciphered as binary numeral strings of
+// the smallest allowed length, cycle walking back into the domain.
+type FF1 struct {
+	c      *ff1Cipher
+	n      int // bits per numeral string
+	domain uint64
+}
+
+// NewFF1 returns the FF1 permutation of [0, domain) keyed by an AES key of
+// 16, 24 or 32 bytes. The tweak may be empty.
+func NewFF1(key, tweak []byte, domain uint64) (*FF1, error) {
+	if domain < 2 {
+		return nil, fmt.Errorf("q3m: FF1 domain %d too small", domain)
+	}
+	c, err := newFF1Cipher(key, tweak, 2)
+	if err != nil {
+		return nil, err
+	}
+	n := max(bits.Len64(domain-1), bits.Len64(ff1MinDomain-1))
+	return &FF1{c: c, n: n, domain: domain}, nil
+}
+
+// Domain returns the size of the permuted range.
+func (f *FF1) Domain() uint64 {
+	return f.domain
+}
+
+// Shuffle enciphers idx, which must be below Domain().
+func (f *FF1) Shuffle(idx uint64) uint64 {
+	for {
+		idx = f.crypt(idx, false)
+		if idx < f.domain {
+			return idx
+		}
+	}
+}
+
+// Unshuffle inverts Shuffle.
+func (f *FF1) Unshuffle(idx uint64) uint64 {
+	for {
+		idx = f.crypt(idx, true)
+		if idx < f.domain {
+			return idx
+		}
+	}
+}
+
+func (f *FF1) crypt(v uint64, decrypt bool) uint64 {
+	x := make([]uint16, f.n)
+	for i := range x {
+		x[f.n-1-i] = uint16(v >> i & 1)
+	}
+	if decrypt {
+		x = f.c.decrypt(x)
+	} else {
+		x = f.c.encrypt(x)
+	}
+	var out uint64
+	for _, b := range x {
+		out = out<<1 | uint64(b)
+	}
+	return out
+}
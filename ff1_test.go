@@ -0,0 +1,140 @@
+package q3m
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+// ff1Vectors are the FF1 samples of the NIST SP 800-38G examples.
+var ff1Vectors = []struct {
+	key, tweak string
+	radix      uint32
+	pt, ct     string
+}{
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "", 10, "0123456789", "2433477484"},
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "39383736353433323130", 10, "0123456789", "6124200773"},
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "3737373770717273373737", 36, "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "", 10, "0123456789", "2830668132"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "39383736353433323130", 10, "0123456789", "2496655549"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "3737373770717273373737", 36, "0123456789abcdefghi", "xbj3kv35jrawxv32ysr"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "", 10, "0123456789", "6657667009"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "39383736353433323130", 10, "0123456789", "1001623463"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "3737373770717273373737", 36, "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+}
+
+const ff1Digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func toNumerals(s string) []uint16 {
+	out := make([]uint16, len(s))
+	for i := range s {
+		for d := range ff1Digits {
+			if ff1Digits[d] == s[i] {
+				out[i] = uint16(d)
+			}
+		}
+	}
+	return out
+}
+
+func fromNumerals(x []uint16) string {
+	out := make([]byte, len(x))
+	for i, d := range x {
+		out[i] = ff1Digits[d]
+	}
+	return string(out)
+}
+
+func TestFF1NISTVectors(t *testing.T) {
+	for i, v := range ff1Vectors {
+		key, _ := hex.DecodeString(v.key)
+		tweak, _ := hex.DecodeString(v.tweak)
+		c, err := newFF1Cipher(key, tweak, v.radix)
+		if err != nil {
+			t.Fatalf("sample %d: %v", i+1, err)
+		}
+		if got := fromNumerals(c.encrypt(toNumerals(v.pt))); got != v.ct {
+			t.Errorf("sample %d: encrypt = %s, want %s", i+1, got, v.ct)
+		}
+		if got := fromNumerals(c.decrypt(toNumerals(v.ct))); got != v.pt {
+			t.Errorf("sample %d: decrypt = %s, want %s", i+1, got, v.pt)
+		}
+	}
+}
+
+var ff1TestKey = []byte("0123456789abcdef")
+
+func TestFF1Permutation(t *testing.T) {
+	f, err := NewFF1(ff1TestKey, nil, TotalCells)
+	if err != nil {
+		t.Fatalf("NewFF1: %v", err)
+	}
+	for _, idx := range []uint64{0, 1, GridWidth, TotalCells / 2, TotalCells - 1} {
+		s := f.Shuffle(idx)
+		if s >= TotalCells {
+			t.Errorf("Shuffle(%d) = %d out of range", idx, s)
+		}
+		if back := f.Unshuffle(s); back != idx {
+			t.Errorf("Unshuffle(Shuffle(%d)) = %d", idx, back)
+		}
+	}
+
+	// Below the SP 800-38G minimum the numeral string is padded to 20 bits,
+	// cycle walking back into the domain.
+	small, err := NewFF1(ff1TestKey, []byte("tweak"), 200_000)
+	if err != nil {
+		t.Fatalf("NewFF1: %v", err)
+	}
+	seen := make(map[uint64]bool)
+	for i := range uint64(500) {
+		s := small.Shuffle(i)
+		if s >= small.Domain() || seen[s] {
+			t.Fatalf("Shuffle(%d) = %d is out of range or repeated", i, s)
+		}
+		seen[s] = true
+		if back := small.Unshuffle(s); back != i {
+			t.Fatalf("Unshuffle(Shuffle(%d)) = %d", i, back)
+		}
+	}
+
+	if _, err := NewFF1([]byte("short"), nil, TotalCells); err == nil {
+		t.Error("NewFF1 accepted a 5-byte key")
+	}
+}
+
+func TestCodecFF1(t *testing.T) {
+	c, err := NewCodec(WithFF1(ff1TestKey, nil))
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	addr, err := c.Encode(eiffel.Lat, eiffel.Lon)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !addr.Private {
+		t.Errorf("FF1 address %s should be private", addr)
+	}
+	got, err := c.Decode(addr.String())
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", addr, err)
+	}
+	if math.Abs(got.Lat-eiffel.Lat) > 0.00002 || math.Abs(got.Lon-eiffel.Lon) > 0.00002 {
+		t.Errorf("round trip %s -> %v", addr, got)
+	}
+
+	// A permutation over another domain is rejected.
+	f, _ := NewFF1(ff1TestKey, nil, 1000)
+	if _, err := NewCodec(WithPermutation(f)); err == nil {
+		t.Error("NewCodec accepted a permutation of the wrong domain")
+	}
+	if _, err := NewCodec(WithFF1([]byte("short"), nil)); err == nil {
+		t.Error("NewCodec accepted a 5-byte FF1 key")
+	}
+}
+
+func BenchmarkFF1Shuffle(b *testing.B) {
+	f, _ := NewFF1(ff1TestKey, nil, TotalCells)
+	for i := 0; i < b.N; i++ {
+		f.Shuffle(uint64(i) % TotalCells)
+	}
+}
@@ -0,0 +1,153 @@
+package q3m
+
+import (
+	"fmt"
+	"math"
+)
+
+// Geodesic returns the length in metres of the shortest path on the GRS80
+// ellipsoid from one WGS84 point to another, and its initial bearing in
+// degrees clockwise from true north, in [0, 360). It uses Vincenty's
+// inverse formula, accurate to a fraction of a millimetre; for nearly
+// antipodal points, where the formula does not converge, both are NaN.
+// The bearing of two identical points is 0.
+func Geodesic(from, to Coordinate) (distance, bearing float64) {
+	const b = grs80A * (1 - grs80F)
+	rad := math.Pi / 180
+
+	L := (to.Lon - from.Lon) * rad
+	u1 := math.Atan((1 - grs80F) * math.Tan(from.Lat*rad))
+	u2 := math.Atan((1 - grs80F) * math.Tan(to.Lat*rad))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := L
+	var sinLambda, cosLambda, sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	converged := false
+	for range 200 {
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, 0 // identical points
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0 // both points on the equator
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		C := grs80F / 16 * cos2Alpha * (4 + grs80F*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = L + (1-C)*grs80F*sinAlpha*
+			(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return math.NaN(), math.NaN()
+	}
+
+	u := cos2Alpha * (grs80A*grs80A - b*b) / (b * b)
+	A := 1 + u/16384*(4096+u*(-768+u*(320-175*u)))
+	B := u / 1024 * (256 + u*(-128+u*(74-47*u)))
+	dSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	distance = b * A * (sigma - dSigma)
+
+	alpha := math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	bearing = math.Mod(alpha/rad+360, 360)
+	return distance, bearing
+}
+
+// Distance returns the geodesic distance in metres between the centres of
+// the cells designated by a and b, which may lie in different grids.
+// Errors are the same as Decode.
+func Distance(a, b Address) (float64, error) {
+	from, to, err := decodePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	d, _ := Geodesic(from, to)
+	return d, nil
+}
+
+// Bearing returns the initial bearing in degrees clockwise from true north,
+// in [0, 360), of the geodesic from the centre of the cell of a to that of
+// b. Errors are the same as Decode.
+func Bearing(a, b Address) (float64, error) {
+	from, to, err := decodePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+	_, bearing := Geodesic(from, to)
+	return bearing, nil
+}
+
+// PlanarDistance returns the distance in metres between the centres of the
+// cells designated by a and b, measured in the plane of their grid
+// (Lambert93, or the UTM zone of an overseas region) and corrected by the
+// scale factor of the projection at their midpoint. The centres are taken
+// in grid coordinates, so that only the midpoint is projected back to
+// WGS84. It is within a centimetre of Distance up to about 25km, and 20cm
+// at 70km: use Distance for longer ranges. Besides the errors of Decode,
+// it fails if a and b lie in different grids.
+func PlanarDistance(a, b Address) (float64, error) {
+	if a.Region != b.Region {
+		return 0, fmt.Errorf("q3m: %s and %s are in different grids", a, b)
+	}
+	c, err := defaultCodec().codecFor(a.String())
+	if err != nil {
+		return 0, err
+	}
+	var e, n [2]float64
+	for i, addr := range [2]Address{a, b} {
+		a, cell, err := c.parse(addr.String())
+		if err != nil {
+			return 0, err
+		}
+		e[i], n[i], _ = c.gridCenter(a, cell)
+	}
+	lat, lon := c.proj.inverse((e[0]+e[1])/2, (n[0]+n[1])/2)
+	return math.Hypot(e[1]-e[0], n[1]-n[0]) / c.proj.scale(lat, lon), nil
+}
+
+// decodePair decodes a and b with DefaultCodec.
+func decodePair(a, b Address) (Coordinate, Coordinate, error) {
+	from, err := Decode(a.String())
+	if err != nil {
+		return Coordinate{}, Coordinate{}, err
+	}
+	to, err := Decode(b.String())
+	if err != nil {
+		return Coordinate{}, Coordinate{}, err
+	}
+	return from, to, nil
+}
+
+// lambert93Scale returns the point scale factor of Lambert93 at the WGS84
+// point (lat, lon): 1 on the standard parallels 44°N and 49°N, below 1
+// between them.
+func lambert93Scale(lat, lon float64) float64 {
+	phi := lat * math.Pi / 180
+	sinPhi := math.Sin(phi)
+	nu := grs80A / math.Sqrt(1-grs80E*grs80E*sinPhi*sinPhi)
+	r := lambert93C * math.Exp(-lambert93N*isoLat(phi, grs80E))
+	return lambert93N * r / (nu * math.Cos(phi))
+}
+
+// utmScale returns the point scale factor of the UTM zone at the WGS84
+// point (lat, lon), from the distance to the central meridian.
+func utmScale(lat, lon float64, zone int, south bool) float64 {
+	phi := lat * math.Pi / 180
+	w := 1 - grs80E*grs80E*math.Pow(math.Sin(phi), 2)
+	// Gaussian radius of curvature sqrt(rho*nu).
+	R := grs80A * math.Sqrt(1-grs80E*grs80E) / w
+	E, _ := ToUTM(lat, lon, zone, south)
+	x := (E - utmE0) / utmK0
+	x2 := x * x / (R * R)
+	return utmK0 * (1 + x2/2 + x2*x2/24)
+}
@@ -0,0 +1,104 @@
+package q3m
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// dms converts degrees, minutes and seconds to decimal degrees.
+func dms(d, m, s float64) float64 {
+	return math.Copysign(math.Abs(d)+m/60+s/3600, d)
+}
+
+func TestGeodesic(t *testing.T) {
+	// Geoscience Australia worked example on GRS80: Flinders Peak to
+	// Buninyong.
+	from := Coordinate{Lat: dms(-37, 57, 3.72030), Lon: dms(144, 25, 29.52440)}
+	to := Coordinate{Lat: dms(-37, 39, 10.15610), Lon: dms(143, 55, 35.38390)}
+	d, b := Geodesic(from, to)
+	if math.Abs(d-54972.271) > 0.001 {
+		t.Errorf("distance = %.4f, want 54972.271", d)
+	}
+	if want := dms(306, 52, 5.37); math.Abs(b-want) > 0.01/3600 {
+		t.Errorf("bearing = %.6f, want %.6f", b, want)
+	}
+
+	if d, b := Geodesic(from, from); d != 0 || b != 0 {
+		t.Errorf("Geodesic(p, p) = %v, %v", d, b)
+	}
+	// Due north and due east.
+	if _, b := Geodesic(Coordinate{Lat: 45, Lon: 2}, Coordinate{Lat: 46, Lon: 2}); math.Abs(b) > 1e-9 {
+		t.Errorf("bearing north = %v", b)
+	}
+	if d, b := Geodesic(Coordinate{Lon: 0}, Coordinate{Lon: 1}); math.Abs(b-90) > 1e-9 || math.Abs(d-111319.491) > 0.001 {
+		t.Errorf("one degree on the equator = %v, %v", d, b)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a, _ := Encode(eiffel.Lat, eiffel.Lon)
+	b, _ := Encode(48.8530, 2.3499) // Notre-Dame
+	d, err := Distance(a, b)
+	if err != nil || d < 4000 || d > 4200 {
+		t.Fatalf("Distance = %v, %v", d, err)
+	}
+	bearing, err := Bearing(a, b)
+	if err != nil || bearing < 90 || bearing > 120 {
+		t.Errorf("Bearing = %v, %v", bearing, err)
+	}
+	if back, _ := Bearing(b, a); math.Abs(back-bearing-180) > 0.1 {
+		t.Errorf("reverse Bearing = %v", back)
+	}
+
+	if _, err := Distance(a, Address{W1: "zzzz", W2: "b", W3: "c"}); !errors.Is(err, ErrUnknownWord) {
+		t.Errorf("Distance to an unknown word: %v", err)
+	}
+}
+
+func TestPlanarDistance(t *testing.T) {
+	pairs := [][2]Coordinate{
+		{eiffel, {Lat: 48.8530, Lon: 2.3499}},
+		{{Lat: 43.2965, Lon: 5.3698}, {Lat: 43.35, Lon: 5.45}},   // Marseille, far from the standard parallels
+		{{Lat: 51.03, Lon: 2.37}, {Lat: 50.95, Lon: 2.5}},        // Dunkerque, beyond 49°N
+		{{Lat: 4.9372, Lon: -52.326}, {Lat: 4.85, Lon: -52.25}},  // Cayenne
+		{{Lat: -20.8789, Lon: 55.4481}, {Lat: -21.0, Lon: 55.3}}, // La Réunion
+	}
+	for _, p := range pairs {
+		a, _ := Encode(p[0].Lat, p[0].Lon)
+		b, _ := Encode(p[1].Lat, p[1].Lon)
+		want, err := Distance(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := PlanarDistance(a, b)
+		if err != nil || math.Abs(got-want) > 0.01 {
+			t.Errorf("PlanarDistance(%s, %s) = %.4f, %v, want %.4f", a, b, got, err, want)
+		}
+	}
+
+	// Sub-cells are measured from their own centre, as by Decode.
+	pa, _ := EncodePrecise(eiffel.Lat, eiffel.Lon, Precision1cm)
+	pb, _ := EncodePrecise(eiffel.Lat+1e-6, eiffel.Lon, Precision1cm)
+	want, _ := Distance(pa, pb)
+	if got, err := PlanarDistance(pa, pb); err != nil || math.Abs(got-want) > 0.001 {
+		t.Errorf("PlanarDistance(%s, %s) = %.4f, %v, want %.4f", pa, pb, got, err, want)
+	}
+
+	a, _ := Encode(eiffel.Lat, eiffel.Lon)
+	b, _ := Encode(-20.8789, 55.4481)
+	if _, err := PlanarDistance(a, b); err == nil {
+		t.Error("PlanarDistance across grids succeeded")
+	}
+}
+
+func TestLambert93Scale(t *testing.T) {
+	for _, lat := range []float64{44, 49} {
+		if k := lambert93Scale(lat, 3); math.Abs(k-1) > 1e-9 {
+			t.Errorf("scale at %v°N = %v, want 1", lat, k)
+		}
+	}
+	if k := lambert93Scale(46.5, 3); k > 0.9991 || k < 0.9990 {
+		t.Errorf("scale at 46.5°N = %v", k)
+	}
+}
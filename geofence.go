@@ -0,0 +1,276 @@
+package q3m
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Zone is a named polygon, made of WGS84 rings combined by the even-odd
+// rule as in CellsInPolygon.
+type Zone struct {
+	Name  string
+	Rings [][]Coordinate
+}
+
+// ReadZones reads the Polygon and MultiPolygon geometries of a GeoJSON
+// feature collection, feature or bare geometry, one zone per geometry;
+// other geometries are skipped. A feature is named by its nameKey property,
+// or else by its id, or else by its position in the collection from 0.
+func ReadZones(r io.Reader, nameKey string) ([]Zone, error) {
+	var obj geoJSONObject
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("q3m: reading GeoJSON: %w", err)
+	}
+	var zones []Zone
+	features := obj.Features
+	if obj.Type != "FeatureCollection" {
+		features = []geoJSONObject{obj}
+	}
+	for i, f := range features {
+		geom := &f
+		if f.Type == "Feature" {
+			geom = f.Geometry
+		}
+		if geom == nil {
+			continue
+		}
+		rings, err := geom.rings()
+		if err != nil {
+			return nil, fmt.Errorf("q3m: reading GeoJSON feature %d: %w", i, err)
+		}
+		if len(rings) > 0 {
+			zones = append(zones, Zone{Name: f.name(nameKey, i), Rings: rings})
+		}
+	}
+	return zones, nil
+}
+
+// geoJSONObject is the part of a GeoJSON object read by ReadZones.
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	ID          any             `json:"id"`
+	Properties  map[string]any  `json:"properties"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+// name returns the name of the i-th feature o.
+func (o geoJSONObject) name(key string, i int) string {
+	if s, ok := o.Properties[key].(string); ok {
+		return s
+	}
+	if o.ID != nil {
+		return fmt.Sprint(o.ID)
+	}
+	return strconv.Itoa(i)
+}
+
+// rings returns the rings of the geometry o, none unless it is a Polygon
+// or a MultiPolygon.
+func (o geoJSONObject) rings() ([][]Coordinate, error) {
+	var polygons [][][][]float64
+	switch o.Type {
+	case "Polygon":
+		var p [][][]float64
+		if err := json.Unmarshal(o.Coordinates, &p); err != nil {
+			return nil, err
+		}
+		polygons = append(polygons, p)
+	case "MultiPolygon":
+		if err := json.Unmarshal(o.Coordinates, &polygons); err != nil {
+			return nil, err
+		}
+	}
+	var rings [][]Coordinate
+	for _, p := range polygons {
+		for _, ring := range p {
+			pts := make([]Coordinate, 0, len(ring))
+			for _, pos := range ring {
+				if len(pos) < 2 {
+					return nil, fmt.Errorf("invalid position %v", pos)
+				}
+				pts = append(pts, Coordinate{Lat: pos[1], Lon: pos[0]})
+			}
+			rings = append(rings, pts)
+		}
+	}
+	return rings, nil
+}
+
+// Geofence answers which zones contain a point. Zones are projected to
+// Lambert93 once and indexed: a lookup only tests the edges of the zones
+// near the point. A Geofence is safe for concurrent use.
+type Geofence struct {
+	zones []fenceZone
+
+	// index holds, for each square of side cell from origin, the zones
+	// whose bounds meet it.
+	index  [][]int32
+	origin [2]float64
+	cell   float64
+	w, h   int
+}
+
+// maxFenceIndex bounds the number of squares of the index of a Geofence.
+const maxFenceIndex = 1 << 16
+
+// fenceZone is a zone of a Geofence, its edges split into horizontal bands
+// of height band from the bottom of its bounds.
+type fenceZone struct {
+	name   string
+	bounds Bounds
+	band   float64
+	bands  [][]polygonEdge
+}
+
+// NewGeofence returns the geofence of zones. Zones sharing a name act as
+// one.
+func NewGeofence(zones ...Zone) *Geofence {
+	g := &Geofence{}
+	all := Bounds{EMin: math.Inf(1), NMin: math.Inf(1), EMax: math.Inf(-1), NMax: math.Inf(-1)}
+	for _, z := range zones {
+		edges, b := polygonEdges(projectRings(z.Rings))
+		if len(edges) == 0 {
+			continue
+		}
+		g.zones = append(g.zones, newFenceZone(z.Name, edges, b))
+		all = Bounds{EMin: min(all.EMin, b.EMin), NMin: min(all.NMin, b.NMin), EMax: max(all.EMax, b.EMax), NMax: max(all.NMax, b.NMax)}
+	}
+	if len(g.zones) == 0 {
+		return g
+	}
+
+	g.origin = [2]float64{all.EMin, all.NMin}
+	g.cell = max(math.Sqrt((all.EMax-all.EMin)*(all.NMax-all.NMin)/maxFenceIndex), 1)
+	g.w = int((all.EMax-all.EMin)/g.cell) + 1
+	g.h = int((all.NMax-all.NMin)/g.cell) + 1
+	g.index = make([][]int32, g.w*g.h)
+	for i, z := range g.zones {
+		x0, y0 := g.square(z.bounds.EMin, z.bounds.NMin)
+		x1, y1 := g.square(z.bounds.EMax, z.bounds.NMax)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				g.index[y*g.w+x] = append(g.index[y*g.w+x], int32(i))
+			}
+		}
+	}
+	return g
+}
+
+// LoadGeofence reads zones as ReadZones does and returns their geofence.
+func LoadGeofence(r io.Reader, nameKey string) (*Geofence, error) {
+	zones, err := ReadZones(r, nameKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewGeofence(zones...), nil
+}
+
+func newFenceZone(name string, edges []polygonEdge, b Bounds) fenceZone {
+	// About 8 edges per band.
+	n := min(max(len(edges)/8, 1), 4096)
+	z := fenceZone{name: name, bounds: b, band: max((b.NMax-b.NMin)/float64(n), 1e-6), bands: make([][]polygonEdge, n)}
+	for _, e := range edges {
+		lo := z.bandOf(min(e.p[1], e.q[1]))
+		hi := z.bandOf(max(e.p[1], e.q[1]))
+		for i := lo; i <= hi; i++ {
+			z.bands[i] = append(z.bands[i], e)
+		}
+	}
+	return z
+}
+
+// bandOf returns the band of z at northing N.
+func (z *fenceZone) bandOf(N float64) int {
+	return min(max(int((N-z.bounds.NMin)/z.band), 0), len(z.bands)-1)
+}
+
+// contains reports whether (E, N) is inside z, by the even-odd rule.
+func (z *fenceZone) contains(E, N float64) bool {
+	b := z.bounds
+	if E < b.EMin || E > b.EMax || N < b.NMin || N > b.NMax {
+		return false
+	}
+	in := false
+	for _, e := range z.bands[z.bandOf(N)] {
+		if (e.p[1] > N) != (e.q[1] > N) && E < e.x(N) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// square returns the index square of (E, N), clamped to the index.
+func (g *Geofence) square(E, N float64) (x, y int) {
+	x = min(max(int((E-g.origin[0])/g.cell), 0), g.w-1)
+	y = min(max(int((N-g.origin[1])/g.cell), 0), g.h-1)
+	return x, y
+}
+
+// Names returns the names of the zones of g, in the order they were given.
+func (g *Geofence) Names() []string {
+	var names []string
+	for _, z := range g.zones {
+		if !slices.Contains(names, z.name) {
+			names = append(names, z.name)
+		}
+	}
+	return names
+}
+
+// LocatePoint returns the names of the zones containing the WGS84 point
+// (lat, lon), in the order they were given.
+func (g *Geofence) LocatePoint(lat, lon float64) []string {
+	if len(g.zones) == 0 {
+		return nil
+	}
+	E, N := ToLambert93(lat, lon)
+	var names []string
+	for _, i := range g.index[g.squareIndex(E, N)] {
+		z := &g.zones[i]
+		if !slices.Contains(names, z.name) && z.contains(E, N) {
+			names = append(names, z.name)
+		}
+	}
+	return names
+}
+
+func (g *Geofence) squareIndex(E, N float64) int {
+	x, y := g.square(E, N)
+	return y*g.w + x
+}
+
+// Locate returns the names of the zones containing the centre of the cell
+// of addr, decoded with DefaultCodec. Errors are the same as Decode.
+func (g *Geofence) Locate(addr Address) ([]string, error) {
+	c, err := Decode(addr.String())
+	if err != nil {
+		return nil, err
+	}
+	return g.LocatePoint(c.Lat, c.Lon), nil
+}
+
+// Contains reports whether the zone named zone contains the centre of the
+// cell of addr. Besides the errors of Decode, it fails if g has no such
+// zone.
+func (g *Geofence) Contains(zone string, addr Address) (bool, error) {
+	if !slices.ContainsFunc(g.zones, func(z fenceZone) bool { return z.name == zone }) {
+		return false, fmt.Errorf("q3m: unknown zone %q", zone)
+	}
+	c, err := Decode(addr.String())
+	if err != nil {
+		return false, err
+	}
+	E, N := ToLambert93(c.Lat, c.Lon)
+	for _, i := range g.index[g.squareIndex(E, N)] {
+		if z := &g.zones[i]; z.name == zone && z.contains(E, N) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,153 @@
+package q3m
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const zonesGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"paris"},"geometry":{"type":"Polygon","coordinates":
+	[[[2.25,48.83],[2.35,48.83],[2.35,48.88],[2.25,48.88],[2.25,48.83]]]}},
+{"type":"Feature","properties":{"name":"anneau"},"geometry":{"type":"Polygon","coordinates":
+	[[[2.2,48.8],[2.4,48.8],[2.4,48.9],[2.2,48.9],[2.2,48.8]],
+	 [[2.29,48.855],[2.30,48.855],[2.30,48.862],[2.29,48.862],[2.29,48.855]]]}},
+{"type":"Feature","id":7,"properties":{},"geometry":{"type":"MultiPolygon","coordinates":
+	[[[[2.34,48.85],[2.36,48.85],[2.36,48.86],[2.34,48.86]]],
+	 [[[5.3,43.2],[5.4,43.2],[5.4,43.3],[5.3,43.3]]]]}},
+{"type":"Feature","properties":{"name":"point"},"geometry":{"type":"Point","coordinates":[2.3,48.8]}},
+{"type":"Feature","properties":{"nom":"sans nom"},"geometry":{"type":"Polygon","coordinates":
+	[[[-4.6,48.3],[-4.4,48.3],[-4.4,48.5]]]}}
+]}`
+
+func TestReadZones(t *testing.T) {
+	zones, err := ReadZones(strings.NewReader(zonesGeoJSON), "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, z := range zones {
+		names = append(names, z.Name)
+	}
+	if want := []string{"paris", "anneau", "7", "4"}; !slices.Equal(names, want) {
+		t.Errorf("zone names = %q, want %q", names, want)
+	}
+	if len(zones[1].Rings) != 2 || len(zones[2].Rings) != 2 || zones[0].Rings[0][1] != (Coordinate{Lat: 48.83, Lon: 2.35}) {
+		t.Errorf("zone rings = %v", zones)
+	}
+
+	if _, err := ReadZones(strings.NewReader(`{"type":"Polygon","coordinates":[[[1]]]}`), "name"); err == nil {
+		t.Error("ReadZones accepted a one-number position")
+	}
+	if _, err := ReadZones(strings.NewReader(`{`), "name"); err == nil {
+		t.Error("ReadZones accepted truncated JSON")
+	}
+}
+
+func TestGeofence(t *testing.T) {
+	g, err := LoadGeofence(strings.NewReader(zonesGeoJSON), "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := g.Names(); !slices.Equal(names, []string{"paris", "anneau", "7", "4"}) {
+		t.Errorf("Names() = %q", names)
+	}
+
+	notreDame, _ := Encode(48.8530, 2.3499)
+	eiffelAddr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	marseille, _ := Encode(43.2965, 5.3698)
+	lyon, _ := Encode(45.76, 4.84)
+	tests := []struct {
+		addr Address
+		want []string
+	}{
+		{eiffelAddr, []string{"paris"}}, // in the hole of anneau
+		{notreDame, []string{"paris", "anneau", "7"}},
+		{marseille, []string{"7"}},
+		{lyon, nil},
+	}
+	for _, tt := range tests {
+		got, err := g.Locate(tt.addr)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Locate(%s) = %q, %v, want %q", tt.addr, got, err, tt.want)
+		}
+	}
+
+	if ok, err := g.Contains("anneau", notreDame); !ok || err != nil {
+		t.Errorf("Contains(anneau, Notre-Dame) = %v, %v", ok, err)
+	}
+	if ok, err := g.Contains("anneau", eiffelAddr); ok || err != nil {
+		t.Errorf("Contains(anneau, Tour Eiffel) = %v, %v", ok, err)
+	}
+	if _, err := g.Contains("lyon", eiffelAddr); err == nil {
+		t.Error("Contains(unknown zone) succeeded")
+	}
+	if _, err := g.Locate(Address{W1: "zzzz", W2: "b", W3: "c"}); err == nil {
+		t.Error("Locate(unknown word) succeeded")
+	}
+
+	if got := NewGeofence().LocatePoint(eiffel.Lat, eiffel.Lon); got != nil {
+		t.Errorf("empty geofence: %q", got)
+	}
+}
+
+// star returns a star of n branches centred on c, in WGS84.
+func star(c Coordinate, n int, r0, r1 float64) []Coordinate {
+	var ring []Coordinate
+	for i := range 2 * n {
+		r := r0
+		if i%2 == 1 {
+			r = r1
+		}
+		a := float64(i) * math.Pi / float64(n)
+		ring = append(ring, Coordinate{Lat: c.Lat + r*math.Sin(a), Lon: c.Lon + r*math.Cos(a)})
+	}
+	return ring
+}
+
+// inRing reports whether p is inside ring in the latitude-longitude plane.
+func inRing(ring []Coordinate, p Coordinate) bool {
+	in := false
+	for i, a := range ring {
+		b := ring[(i+1)%len(ring)]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) && p.Lon < a.Lon+(p.Lat-a.Lat)*(b.Lon-a.Lon)/(b.Lat-a.Lat) {
+			in = !in
+		}
+	}
+	return in
+}
+
+func TestGeofenceStar(t *testing.T) {
+	ring := star(Coordinate{Lat: 46.5, Lon: 2.5}, 150, 0.2, 0.05)
+	g := NewGeofence(Zone{Name: "étoile", Rings: [][]Coordinate{ring}})
+	rng := rand.New(rand.NewPCG(1, 2))
+	inside := 0
+	for range 5000 {
+		p := Coordinate{Lat: 46.3 + 0.4*rng.Float64(), Lon: 2.3 + 0.4*rng.Float64()}
+		want := inRing(ring, p)
+		if got := len(g.LocatePoint(p.Lat, p.Lon)) == 1; got != want {
+			t.Errorf("LocatePoint(%v) = %v, want %v", p, got, want)
+		}
+		if want {
+			inside++
+		}
+	}
+	if inside < 500 {
+		t.Errorf("only %d points inside the star", inside)
+	}
+}
+
+func BenchmarkGeofenceLocatePoint(b *testing.B) {
+	var zones []Zone
+	for i := range 100 {
+		c := Coordinate{Lat: 43 + float64(i/10)*0.5, Lon: -1 + float64(i%10)*0.8}
+		zones = append(zones, Zone{Name: string(rune('a' + i%26)), Rings: [][]Coordinate{star(c, 200, 0.3, 0.1)}})
+	}
+	g := NewGeofence(zones...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.LocatePoint(45.5, 2.5)
+	}
+}
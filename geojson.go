@@ -0,0 +1,138 @@
+package q3m
+
+import "fmt"
+
+// GeoJSON (RFC 7946) types, limited to what q3m emits. Positions are
+// [longitude, latitude] pairs in WGS84.
+
+// Geometry is a GeoJSON geometry object.
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection returns a collection holding features.
+func NewFeatureCollection(features ...Feature) *FeatureCollection {
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	fc.Add(features...)
+	return fc
+}
+
+// Add appends features to fc.
+func (fc *FeatureCollection) Add(features ...Feature) {
+	fc.Features = append(fc.Features, features...)
+}
+
+// position returns c as a GeoJSON position.
+func (c Coordinate) position() [2]float64 {
+	return [2]float64{c.Lon, c.Lat}
+}
+
+// PointFeature returns a Point feature at c. A nil props yields an empty
+// properties object.
+func PointFeature(c Coordinate, props map[string]any) Feature {
+	if props == nil {
+		props = map[string]any{}
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Point", Coordinates: c.position()},
+		Properties: props,
+	}
+}
+
+// Properties returns the GeoJSON properties describing a: its address,
+// resolution and words, leaving out the words a coarser resolution does not
+// use, then its region and privacy if set.
+func (a Address) Properties() map[string]any {
+	props := map[string]any{
+		"address":    a.String(),
+		"resolution": a.Resolution.String(),
+	}
+	for i, w := range []string{a.W1, a.W2, a.W3, a.W4} {
+		if w != "" {
+			props[fmt.Sprintf("w%d", i+1)] = w
+		}
+	}
+	if a.Region != "" {
+		props["region"] = a.Region
+	}
+	if a.Private {
+		props["private"] = true
+	}
+	return props
+}
+
+// GeoJSON returns the footprint of c as a Polygon feature whose properties
+// hold the cell address and words. The exterior ring runs counter-clockwise
+// from the south-west corner.
+func (c Cell) GeoJSON() Feature {
+	return polygonFeature(c.Corners(), c.Address().Properties())
+}
+
+// AddressGeoJSON returns the footprint of address with DefaultCodec (see
+// Codec.GeoJSON).
+func AddressGeoJSON(address string) (Feature, error) {
+	return defaultCodec().GeoJSON(address)
+}
+
+// GeoJSON returns the footprint of address as a Polygon feature whose
+// properties hold the address as parsed by c, so that a private address
+// does not reveal its public counterpart, and whose ring is its cell at the
+// resolution of the address, or for a four-word address the 10cm or 1cm
+// sub-cell named by the fourth word. Errors are the same as Decode.
+func (c *Codec) GeoJSON(address string) (Feature, error) {
+	c, err := c.codecFor(address)
+	if err != nil {
+		return Feature{}, err
+	}
+	a, cell, err := c.parse(address)
+	if err != nil {
+		return Feature{}, err
+	}
+	g := c.levels[cell.res].grid
+	size := float64(g.size)
+	e := g.bounds.EMin + float64(cell.idx%g.width)*size
+	n := g.bounds.NMin + float64(cell.idx/g.width)*size
+	if a.W4 != "" {
+		i, _ := c.dict.Index(a.W4)
+		p, x, y, _ := subCell(i)
+		size = p.Size()
+		e += float64(x) * size
+		n += float64(y) * size
+	}
+	pts := [4][2]float64{{e, n}, {e + size, n}, {e + size, n + size}, {e, n + size}}
+	var corners [4]Coordinate
+	for i, p := range pts {
+		corners[i].Lat, corners[i].Lon = c.proj.inverse(p[0], p[1])
+	}
+	return polygonFeature(corners, a.Properties()), nil
+}
+
+// polygonFeature returns the Polygon feature closing the ring of corners.
+func polygonFeature(corners [4]Coordinate, props map[string]any) Feature {
+	ring := make([][2]float64, 0, 5)
+	for _, p := range corners {
+		ring = append(ring, p.position())
+	}
+	ring = append(ring, corners[0].position())
+
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+		Properties: props,
+	}
+}
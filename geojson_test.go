@@ -0,0 +1,144 @@
+package q3m
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCellGeoJSON(t *testing.T) {
+	c, _ := CellOf(48.8584, 2.2945)
+	f := c.GeoJSON()
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got struct {
+		Type     string `json:"type"`
+		Geometry struct {
+			Type        string         `json:"type"`
+			Coordinates [][][2]float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]string `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, data)
+	}
+	if got.Type != "Feature" || got.Geometry.Type != "Polygon" {
+		t.Fatalf("type = %q/%q, want Feature/Polygon", got.Type, got.Geometry.Type)
+	}
+	if len(got.Geometry.Coordinates) != 1 || len(got.Geometry.Coordinates[0]) != 5 {
+		t.Fatalf("coordinates = %v, want one closed ring of 5 positions", got.Geometry.Coordinates)
+	}
+	ring := got.Geometry.Coordinates[0]
+	if ring[0] != ring[4] {
+		t.Errorf("ring not closed: %v", ring)
+	}
+	corners := c.Corners()
+	if ring[0] != [2]float64{corners[0].Lon, corners[0].Lat} {
+		t.Errorf("first position = %v, want [lon, lat] of the SW corner", ring[0])
+	}
+	// Counter-clockwise exterior ring: positive shoelace area.
+	area := 0.0
+	for i := 0; i < 4; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	if area <= 0 {
+		t.Errorf("exterior ring is not counter-clockwise (area %g)", area)
+	}
+	addr := c.Address()
+	if got.Properties["address"] != addr.String() || got.Properties["w2"] != addr.W2 {
+		t.Errorf("properties = %v, want address %s", got.Properties, addr)
+	}
+}
+
+func TestFeatureCollection(t *testing.T) {
+	fc := NewFeatureCollection()
+	data, _ := json.Marshal(fc)
+	if string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("empty collection = %s", data)
+	}
+
+	fc.Add(PointFeature(Coordinate{Lat: 48.8584, Lon: 2.2945}, nil))
+	data, _ = json.Marshal(fc)
+	want := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[2.2945,48.8584]},"properties":{}}]}`
+	if string(data) != want {
+		t.Errorf("collection = %s, want %s", data, want)
+	}
+}
+
+func TestCodecGeoJSON(t *testing.T) {
+	c, err := NewCodec(WithSecret([]byte("nid de gypaète, ne pas diffuser")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := c.Encode(48.8584, 2.2945)
+	f, err := c.GeoJSON(addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Properties["address"] != addr.String() || f.Properties["private"] != true {
+		t.Errorf("properties = %v, want the private address %s", f.Properties, addr)
+	}
+	cell, _ := CellOf(48.8584, 2.2945)
+	if got, want := f.Geometry.Coordinates, cell.GeoJSON().Geometry.Coordinates; !reflect.DeepEqual(got, want) {
+		t.Errorf("ring = %v, want the public cell %v", got, want)
+	}
+
+	if _, err := c.GeoJSON("province.shootons.retirons"); !errors.Is(err, ErrNamespace) {
+		t.Errorf("GeoJSON(public address) error = %v, want ErrNamespace", err)
+	}
+}
+
+func TestCodecGeoJSONSubCell(t *testing.T) {
+	for _, p := range []Precision{Precision10cm, Precision1cm} {
+		addr, _ := EncodePrecise(48.8584, 2.2945, p)
+		f, err := AddressGeoJSON(addr.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring := f.Geometry.Coordinates.([][][2]float64)[0]
+		sw, ne := ring[0], ring[2]
+		// A metre is about 9e-6 degree of latitude.
+		if d := (ne[1] - sw[1]) / 9e-6; d < p.Size()*0.9 || d > p.Size()*1.1 {
+			t.Errorf("%s sub-cell spans %.3fm north-south, want %gm", p, d, p.Size())
+		}
+		center, _, _ := DecodePrecise(addr.String())
+		if center.Lat < sw[1] || center.Lat > ne[1] || center.Lon < sw[0] || center.Lon > ne[0] {
+			t.Errorf("%s: centre %v outside the ring %v", p, center, ring)
+		}
+		if f.Properties["w4"] != addr.W4 {
+			t.Errorf("%s: properties = %v", p, f.Properties)
+		}
+	}
+}
+
+func TestAddressProperties(t *testing.T) {
+	for _, r := range []Resolution{Res1m, Res100m, Res1km} {
+		addr, err := EncodeAt(48.8584, 2.2945, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := addr.Properties()
+		if props["address"] != addr.String() || props["resolution"] != r.String() {
+			t.Errorf("%s: properties = %v", r, props)
+		}
+		words := []string{addr.W1, addr.W2, addr.W3}
+		for i, w := range words {
+			key := "w" + string(rune('1'+i))
+			if v, ok := props[key]; ok != (w != "") || ok && v != w {
+				t.Errorf("%s: %s = %v, want %q", r, key, v, w)
+			}
+		}
+		if _, ok := props["region"]; ok {
+			t.Errorf("%s: region set for a metropolitan address", r)
+		}
+	}
+
+	re, _ := Encode(-20.8789, 55.4481)
+	if props := re.Properties(); props["region"] != "re" || props["resolution"] != "1m" {
+		t.Errorf("overseas properties = %v", props)
+	}
+}
@@ -1,6 +1,9 @@
 package q3m
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // Grid bounds in Lambert93 metres.
 const (
@@ -17,23 +20,83 @@ const (
 	TotalCells uint64 = GridWidth * GridHeight // 1_230_500_000_000
 )
 
+// grid is a rectangular grid of square cells over Lambert93, size metres
+// wide, indexed row by row from the south-west corner.
+type grid struct {
+	bounds        Bounds
+	size          uint64
+	width, height uint64
+}
+
+// defaultGrid is the metropolitan France grid of 1m cells. IMMUTABLE.
+var defaultGrid = grid{
+	bounds: Bounds{EMin: EMin, NMin: NMin, EMax: EMax, NMax: NMax},
+	size:   1,
+	width:  GridWidth,
+	height: GridHeight,
+}
+
+// newGrid returns the grid of 1m cells covering b, whose edges must fall on
+// whole metres.
+func newGrid(b Bounds) (grid, error) {
+	for _, v := range []float64{b.EMin, b.NMin, b.EMax, b.NMax} {
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return grid{}, fmt.Errorf("q3m: grid bounds %v are not whole metres", b)
+		}
+	}
+	if b.EMin >= b.EMax || b.NMin >= b.NMax {
+		return grid{}, fmt.Errorf("q3m: grid bounds %v are empty", b)
+	}
+	return grid{
+		bounds: b,
+		size:   1,
+		width:  uint64(b.EMax - b.EMin),
+		height: uint64(b.NMax - b.NMin),
+	}, nil
+}
+
+// coarsen returns the grid of size x size cells aggregating the 1m cells of
+// g, or false if the sides of g are not multiples of size.
+func (g grid) coarsen(size uint64) (grid, bool) {
+	if g.size != 1 || g.width%size != 0 || g.height%size != 0 {
+		return grid{}, false
+	}
+	return grid{bounds: g.bounds, size: size, width: g.width / size, height: g.height / size}, true
+}
+
+// total returns the number of cells of g.
+func (g grid) total() uint64 {
+	return g.width * g.height
+}
+
+// cellIndex returns the index of the cell of g containing (E, N).
+func (g grid) cellIndex(E, N float64) (uint64, bool) {
+	b := g.bounds
+	if E < b.EMin || E >= b.EMax || N < b.NMin || N >= b.NMax {
+		return 0, false
+	}
+	// Clamp against the division rounding up on the last row or column.
+	x := min(uint64(math.Floor((E-b.EMin)/float64(g.size))), g.width-1)
+	y := min(uint64(math.Floor((N-b.NMin)/float64(g.size))), g.height-1)
+	return y*g.width + x, true
+}
+
+// cellCenter returns the Lambert93 centre of the cell idx of g.
+func (g grid) cellCenter(idx uint64) (E, N float64) {
+	size := float64(g.size)
+	E = g.bounds.EMin + (float64(idx%g.width)+0.5)*size
+	N = g.bounds.NMin + (float64(idx/g.width)+0.5)*size
+	return
+}
+
 // CellIndex returns the grid cell index for the given Lambert93 coordinates.
 // Returns false if the point is outside the grid.
 func CellIndex(E, N float64) (uint64, bool) {
-	if E < EMin || E >= EMax || N < NMin || N >= NMax {
-		return 0, false
-	}
-	x := uint64(math.Floor(E - EMin))
-	y := uint64(math.Floor(N - NMin))
-	return y*GridWidth + x, true
+	return defaultGrid.cellIndex(E, N)
 }
 
 // CellCenter returns the Lambert93 coordinates of the centre of the cell
 // identified by idx (+0.5m offset).
 func CellCenter(idx uint64) (E, N float64) {
-	x := idx % GridWidth
-	y := idx / GridWidth
-	E = EMin + float64(x) + 0.5
-	N = NMin + float64(y) + 0.5
-	return
+	return defaultGrid.cellCenter(idx)
 }
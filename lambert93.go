@@ -64,3 +64,17 @@ func FromLambert93(E, N float64) (lat, lon float64) {
 	lon = lambda * 180 / math.Pi
 	return
 }
+
+// projection maps WGS84 coordinates to the plane of a grid, in metres.
+type projection interface {
+	forward(lat, lon float64) (x, y float64)
+	inverse(x, y float64) (lat, lon float64)
+	scale(lat, lon float64) float64 // point scale factor
+}
+
+// lambert93 is the projection of the metropolitan grid.
+type lambert93 struct{}
+
+func (lambert93) forward(lat, lon float64) (float64, float64) { return ToLambert93(lat, lon) }
+func (lambert93) inverse(E, N float64) (float64, float64)     { return FromLambert93(E, N) }
+func (lambert93) scale(lat, lon float64) float64              { return lambert93Scale(lat, lon) }
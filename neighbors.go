@@ -0,0 +1,76 @@
+package q3m
+
+import "fmt"
+
+// Neighbors returns the addresses of the (up to) 8 cells surrounding addr, at
+// its resolution, rows north to south and west to east within a row. Cells
+// beyond the grid edges are omitted.
+func Neighbors(addr Address) ([]Address, error) {
+	return Ring(addr, 1)
+}
+
+// Ring returns the addresses of the cells at exactly k cells (Chebyshev
+// distance) from addr, rows north to south and west to east within a row.
+// Ring(addr, 0) is addr itself. Cells beyond the grid edges are omitted.
+func Ring(addr Address, k int) ([]Address, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("q3m: negative ring radius %d", k)
+	}
+	c, err := addr.Cell()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Address
+	for dy := k; dy >= -k; dy-- {
+		step := 1
+		if dy != k && dy != -k {
+			step = 2 * k // only the west and east edges of middle rows
+		}
+		for dx := -k; dx <= k; dx += step {
+			if n, ok := c.offset(dx, dy); ok {
+				out = append(out, n.Address())
+			}
+		}
+	}
+	return out, nil
+}
+
+// Window returns the (2k+1) x (2k+1) block of addresses centred on addr,
+// rows north to south and west to east within a row. Cells beyond the grid
+// edges are left as the zero Address.
+func Window(addr Address, k int) ([][]Address, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("q3m: negative window radius %d", k)
+	}
+	c, err := addr.Cell()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]Address, 0, 2*k+1)
+	for dy := k; dy >= -k; dy-- {
+		row := make([]Address, 0, 2*k+1)
+		for dx := -k; dx <= k; dx++ {
+			var a Address
+			if n, ok := c.offset(dx, dy); ok {
+				a = n.Address()
+			}
+			row = append(row, a)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// offset returns the cell of the same resolution dx columns east and dy
+// rows north of c, or false if it falls outside the grid.
+func (c Cell) offset(dx, dy int) (Cell, bool) {
+	g := c.grid()
+	col := int64(c.Col()) + int64(dx)
+	row := int64(c.Row()) + int64(dy)
+	if col < 0 || row < 0 || col >= int64(g.width) || row >= int64(g.height) {
+		return Cell{}, false
+	}
+	return Cell{idx: uint64(row)*g.width + uint64(col), res: c.res, reg: c.reg}, true
+}
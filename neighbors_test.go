@@ -0,0 +1,120 @@
+package q3m
+
+import "testing"
+
+// lambertAddress returns the address of the cell containing (E, N).
+func lambertAddress(t *testing.T, E, N float64) Address {
+	t.Helper()
+	c, ok := CellAt(E, N)
+	if !ok {
+		t.Fatalf("CellAt(%f, %f) out of grid", E, N)
+	}
+	return c.Address()
+}
+
+func TestNeighbors(t *testing.T) {
+	E, N := 652469.5, 6862035.5
+	got, err := Neighbors(lambertAddress(t, E, N))
+	if err != nil {
+		t.Fatalf("Neighbors: %v", err)
+	}
+	want := []Address{
+		lambertAddress(t, E-1, N+1), lambertAddress(t, E, N+1), lambertAddress(t, E+1, N+1),
+		lambertAddress(t, E-1, N), lambertAddress(t, E+1, N),
+		lambertAddress(t, E-1, N-1), lambertAddress(t, E, N-1), lambertAddress(t, E+1, N-1),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Neighbors returned %d cells, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Neighbors[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNeighborsGridCorner(t *testing.T) {
+	got, err := Neighbors(lambertAddress(t, EMin, NMin))
+	if err != nil {
+		t.Fatalf("Neighbors: %v", err)
+	}
+	want := []Address{
+		lambertAddress(t, EMin, NMin+1), lambertAddress(t, EMin+1, NMin+1),
+		lambertAddress(t, EMin+1, NMin),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Neighbors at origin returned %d cells, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Neighbors[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNeighborsGridEastEdge(t *testing.T) {
+	// The easternmost column must not wrap around to the next row.
+	got, err := Neighbors(lambertAddress(t, EMax-0.5, 6862035.5))
+	if err != nil {
+		t.Fatalf("Neighbors: %v", err)
+	}
+	if len(got) != 5 {
+		t.Errorf("Neighbors on the east edge returned %d cells, want 5", len(got))
+	}
+}
+
+func TestRingSizes(t *testing.T) {
+	addr := lambertAddress(t, 652469.5, 6862035.5)
+	for k := 0; k <= 4; k++ {
+		got, err := Ring(addr, k)
+		if err != nil {
+			t.Fatalf("Ring(%d): %v", k, err)
+		}
+		want := 8 * k
+		if k == 0 {
+			want = 1
+		}
+		if len(got) != want {
+			t.Errorf("Ring(%d) has %d cells, want %d", k, len(got), want)
+		}
+	}
+	if got, _ := Ring(addr, 0); got[0] != addr {
+		t.Errorf("Ring(0) = %v, want %s", got, addr)
+	}
+	if _, err := Ring(addr, -1); err == nil {
+		t.Error("Ring with negative radius should fail")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	E, N := EMin+0.5, 6862035.5
+	rows, err := Window(lambertAddress(t, E, N), 2)
+	if err != nil {
+		t.Fatalf("Window: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Window has %d rows, want 5", len(rows))
+	}
+	for r, row := range rows {
+		if len(row) != 5 {
+			t.Fatalf("row %d has %d cells, want 5", r, len(row))
+		}
+		// The two western columns fall outside the grid.
+		if row[0] != (Address{}) || row[1] != (Address{}) {
+			t.Errorf("row %d: cells west of the grid should be empty", r)
+		}
+		want := lambertAddress(t, E+2, N+float64(2-r))
+		if row[4] != want {
+			t.Errorf("row %d east cell = %s, want %s", r, row[4], want)
+		}
+	}
+	if rows[2][2] != lambertAddress(t, E, N) {
+		t.Errorf("centre = %s, want the input address", rows[2][2])
+	}
+}
+
+func TestNeighborsUnknownWord(t *testing.T) {
+	if _, err := Neighbors(Address{W1: "xyzzy", W2: "hello", W3: "world"}); err == nil {
+		t.Error("Neighbors with unknown words should fail")
+	}
+}
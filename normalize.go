@@ -0,0 +1,206 @@
+package q3m
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalization is a change made by NormalizeAddress to an address as typed
+// or pasted by a user.
+type Normalization string
+
+const (
+	NormalizedCase        Normalization = "case"        // upper-case letters lowered
+	NormalizedAccents     Normalization = "accents"     // accents and ligatures removed
+	NormalizedWidth       Normalization = "width"       // full-width forms narrowed
+	NormalizedSeparator   Normalization = "separator"   // words split otherwise than by one dot
+	NormalizedPrefix      Normalization = "prefix"      // leading "///" removed
+	NormalizedPunctuation Normalization = "punctuation" // surrounding quotes or punctuation removed
+
+	// NormalizedPhonetic is never reported by NormalizeAddress: it marks
+	// words replaced by a sound-alike, as by PhoneticAddress.
+	NormalizedPhonetic Normalization = "phonetic"
+)
+
+// foldedLetters maps accented letters and ligatures to plain letters. It is
+// a hand table, not an NFD decomposition, and folds exactly the lowercase
+// letters listed here (upper-case ones are lowered first): the accented
+// a, c, e, i, n, o, u, y of Latin-1 and Latin Extended-A, the accented g,
+// r, s, t, z of Latin Extended-A, the dotless ı, and œ, æ, ß, ø, ł, đ.
+// Other precomposed letters (ð, þ, ď, ĥ, ĵ, ķ, ļ, ŀ, ħ, Latin Extended-B and
+// beyond) are kept. Combining accents (category Mn), as left by an NFD
+// decomposition, are dropped separately.
+var foldedLetters = func() map[rune]string {
+	m := map[rune]string{'œ': "oe", 'æ': "ae", 'ß': "ss", 'ø': "o", 'ł': "l", 'đ': "d"}
+	for base, letters := range map[string]string{
+		"a": "àáâãäåāăą",
+		"c": "çćĉċč",
+		"e": "èéêëēĕėęě",
+		"i": "ìíîïĩīĭįı",
+		"n": "ñńņňŉ",
+		"o": "òóôõöōŏő",
+		"u": "ùúûüũūŭůűų",
+		"y": "ýÿŷ",
+		"z": "źżž",
+		"s": "śŝşš",
+		"g": "ĝğġģ",
+		"r": "ŕŗř",
+		"t": "ţťŧ",
+	} {
+		for _, r := range letters {
+			m[r] = base
+		}
+	}
+	return m
+}()
+
+// isAddressSeparator reports whether r may separate the words of a typed
+// address: a dot, a space, a dash, a slash or another common punctuation
+// mark between words.
+func isAddressSeparator(r rune) bool {
+	switch r {
+	case '.', '-', '_', '/', '\\', ',', ';', '·', '•', '‧', '・', '。', '․', '‐', '‑', '–', '—':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+// isAddressMarker reports whether r is one of the markers of an address
+// (PrivateMarker, RegionMarker, ResolutionMarker), kept as they are.
+func isAddressMarker(r rune) bool {
+	return r == '~' || r == ':' || r == '@'
+}
+
+// NormalizeAddress rewrites an address as typed or pasted by a user, such
+// as "///Province Shootons Retirons", into the canonical lowercase, dotted
+// form accepted by Decode, and lists the changes made, in the order of the
+// Normalization constants. Surrounding spaces are removed without being
+// reported. The result is not validated: see ParseAddress.
+//
+// Letters are folded to lowercase and stripped of their accents, whether
+// precomposed or combining; full-width forms are narrowed; any run of
+// spaces, dashes, slashes, underscores, commas or dots (including
+// full-width and middle dots) between two words becomes a single dot; a
+// leading "///" and surrounding quotes, brackets or punctuation are
+// removed. The markers "~", ":" and "@" are left in place.
+func NormalizeAddress(s string) (string, []Normalization) {
+	s = strings.TrimSpace(s)
+	if canonicalAddress(s) {
+		return s, nil
+	}
+	applied := make(map[Normalization]bool)
+
+	// Fold each letter.
+	var folded []rune
+	for _, r := range s {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+			applied[NormalizedWidth] = true
+		case r == 0x3000:
+			r = ' '
+			applied[NormalizedWidth] = true
+		}
+		if l := unicode.ToLower(r); l != r {
+			r = l
+			applied[NormalizedCase] = true
+		}
+		if base, ok := foldedLetters[r]; ok {
+			folded = append(folded, []rune(base)...)
+			applied[NormalizedAccents] = true
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			applied[NormalizedAccents] = true
+			continue
+		}
+		folded = append(folded, r)
+	}
+
+	// Strip what surrounds the address.
+	keep := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || isAddressMarker(r) }
+	start, end := 0, len(folded)
+	for start < end && !keep(folded[start]) {
+		start++
+	}
+	for end > start && !keep(folded[end-1]) {
+		end--
+	}
+	lead := strings.TrimSpace(string(folded[:start]))
+	if strings.Contains(lead, "///") {
+		applied[NormalizedPrefix] = true
+		lead = strings.Replace(lead, "///", "", 1)
+	}
+	if lead != "" || strings.TrimSpace(string(folded[end:])) != "" {
+		applied[NormalizedPunctuation] = true
+	}
+
+	// Join the words with single dots.
+	var b strings.Builder
+	body := folded[start:end]
+	for i := 0; i < len(body); {
+		if !isAddressSeparator(body[i]) {
+			b.WriteRune(body[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(body) && isAddressSeparator(body[j]) {
+			j++
+		}
+		if j-i != 1 || body[i] != '.' {
+			applied[NormalizedSeparator] = true
+		}
+		// Separators next to a marker are dropped: "re: abjurer".
+		if !isAddressMarker(body[i-1]) && !isAddressMarker(body[j]) {
+			b.WriteByte('.')
+		} else {
+			applied[NormalizedSeparator] = true
+		}
+		i = j
+	}
+
+	var changes []Normalization
+	for _, n := range []Normalization{NormalizedCase, NormalizedAccents, NormalizedWidth, NormalizedSeparator, NormalizedPrefix, NormalizedPunctuation} {
+		if applied[n] {
+			changes = append(changes, n)
+		}
+	}
+	return b.String(), changes
+}
+
+// canonicalAddress reports whether s needs no normalization: it only holds
+// lowercase ASCII letters, digits, markers and single dots between words.
+func canonicalAddress(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '~', c == ':', c == '@':
+		case c == '.':
+			if i == 0 || i == len(s)-1 || s[i+1] == '.' || isAddressMarker(rune(s[i-1])) || isAddressMarker(rune(s[i+1])) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeAddress returns the canonical form of address.
+func normalizeAddress(address string) string {
+	s, _ := NormalizeAddress(address)
+	return s
+}
+
+// splitAddress splits the canonical address s into its head, the private
+// marker and region prefix if any, its words, and its resolution marker
+// followed by the resolution, if any. Joining them back yields s.
+func splitAddress(s string) (head string, words []string, res string) {
+	if i := strings.LastIndexAny(s, PrivateMarker+RegionMarker); i >= 0 {
+		head, s = s[:i+1], s[i+1:]
+	}
+	if i := strings.Index(s, ResolutionMarker); i >= 0 {
+		s, res = s[:i], s[i:]
+	}
+	return head, strings.Split(s, "."), res
+}
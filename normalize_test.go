@@ -0,0 +1,61 @@
+package q3m
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		in, want string
+		changes  []Normalization
+	}{
+		{"province.shootons.retirons", "province.shootons.retirons", nil},
+		{"  province.shootons.retirons\n", "province.shootons.retirons", nil},
+		{"Province Shootons Retirons", "province.shootons.retirons", []Normalization{NormalizedCase, NormalizedSeparator}},
+		{"///province.shootons.retirons", "province.shootons.retirons", []Normalization{NormalizedPrefix}},
+		{"province-shootons-retirons", "province.shootons.retirons", []Normalization{NormalizedSeparator}},
+		{"province · shootons · retirons", "province.shootons.retirons", []Normalization{NormalizedSeparator}},
+		{"ｐｒｏｖｉｎｃｅ．ｓｈｏｏｔｏｎｓ．ｒｅｔｉｒｏｎｓ", "province.shootons.retirons", []Normalization{NormalizedWidth}},
+		{"élève.çà.cœur", "eleve.ca.coeur", []Normalization{NormalizedAccents}},
+		{"ÉLÈVE.a.b", "eleve.a.b", []Normalization{NormalizedCase, NormalizedAccents}},
+		{`« province.shootons.retirons ».`, "province.shootons.retirons", []Normalization{NormalizedPunctuation}},
+		{"(///province..shootons.retirons)", "province.shootons.retirons", []Normalization{NormalizedSeparator, NormalizedPrefix, NormalizedPunctuation}},
+		{"RE: abjurer ramassis rossant", "re:abjurer.ramassis.rossant", []Normalization{NormalizedCase, NormalizedSeparator}},
+		{"~a.b.c @ 10m", "~a.b.c@10m", []Normalization{NormalizedSeparator}},
+		{":a.b.c", ":a.b.c", nil},
+	}
+	for _, tt := range tests {
+		got, changes := NormalizeAddress(tt.in)
+		if got != tt.want || !slices.Equal(changes, tt.changes) {
+			t.Errorf("NormalizeAddress(%q) = %q, %q, want %q, %q", tt.in, got, changes, tt.want, tt.changes)
+		}
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	want, err := Decode("province.shootons.retirons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"Province Shootons Retirons",
+		"///province.shootons.retirons",
+		"province-shootons-retirons",
+		"PROVINCE・SHOOTONS・RETIRONS",
+		"\"próvince, shootons, retirons\"",
+	} {
+		if got, err := Decode(s); err != nil || got != want {
+			t.Errorf("Decode(%q) = %v, %v, want %v", s, got, err, want)
+		}
+		if a, err := ParseAddress(s); err != nil || a.String() != "province.shootons.retirons" {
+			t.Errorf("ParseAddress(%q) = %v, %v", s, a, err)
+		}
+	}
+
+	var ae *AddressError
+	if _, err := Decode("Provinxe Shootons Retirons"); !errors.As(err, &ae) || ae.Token != "provinxe" || ae.Address != "Provinxe Shootons Retirons" {
+		t.Errorf("Decode(unknown word) error = %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package q3m
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// phoneticEndings maps silent or equivalent French word endings to the
+// phoneme closing the key, longest first: verb forms and plurals pronounced
+// alike (abattais, abattait, abattaient) share a key.
+var phoneticEndings = []struct{ from, to string }{
+	{"aient", "E"}, {"ais", "E"}, {"ait", "E"}, {"et", "E"},
+	{"ent", ""}, {"es", ""}, {"ez", "e"}, {"er", "e"}, {"ai", "e"},
+	{"e", ""}, {"s", ""}, {"x", ""}, {"t", ""}, {"d", ""},
+}
+
+// phoneticRules are the spellings of French sounds, tried longest first at
+// each position of a word stem. A rule with a non-empty next only applies
+// when the following letter is one of next; "^" in next stands for a
+// consonant or the end of the word, as nasal vowels require.
+//
+// Phonemes are written with one letter each: a, e (é), E (è), i, o, u,
+// U (ou), O (eu), A (an), I (in), N (on), S (ch), j (ge), y (ill), w, and
+// the plain consonants.
+var phoneticRules = []struct {
+	from, to, next string
+}{
+	{"eau", "o", ""},
+	{"ill", "y", ""},
+	{"tion", "syN", ""},
+	{"sch", "S", ""},
+	{"oin", "wI", "^"},
+	{"ain", "I", "^"}, {"ein", "I", "^"}, {"aim", "I", "^"}, {"eim", "I", "^"},
+	{"oeu", "O", ""},
+	{"au", "o", ""},
+	{"ou", "U", ""},
+	{"oi", "wa", ""},
+	{"ai", "E", ""}, {"ei", "E", ""},
+	{"eu", "O", ""},
+	{"an", "A", "^"}, {"am", "A", "^"}, {"en", "A", "^"}, {"em", "A", "^"},
+	{"in", "I", "^"}, {"im", "I", "^"}, {"yn", "I", "^"}, {"ym", "I", "^"}, {"un", "I", "^"}, {"um", "I", "^"},
+	{"on", "N", "^"}, {"om", "N", "^"},
+	{"ch", "S", ""}, {"sh", "S", ""},
+	{"ph", "f", ""},
+	{"th", "t", ""},
+	{"gn", "ny", ""},
+	{"qu", "k", ""},
+	{"gu", "g", "eiy"},
+	{"ge", "j", "aou"},
+	{"sc", "s", "eiy"},
+	{"ck", "k", ""},
+	{"cc", "ks", "eiy"},
+	{"c", "s", "eiy"},
+	{"g", "j", "eiy"},
+	{"c", "k", ""}, {"q", "k", ""},
+	{"x", "ks", ""},
+	{"w", "v", ""},
+	{"h", "", ""},
+	{"y", "i", ""},
+}
+
+// isVowel reports whether the letter b is a vowel.
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiouy", b) >= 0
+}
+
+// PhoneticKey returns a key of the French pronunciation of word, so that
+// words sounding alike share it: "abattait" and "abattais", "abatte" and
+// "abattent", "chaussons" and "shosson". It folds case and accents, strips
+// the silent endings of plurals and verb forms, then spells each sound with
+// a single letter, merging doubled letters. The key is meant for
+// comparison only; it does not follow any published alphabet.
+func PhoneticKey(word string) string {
+	w, _ := NormalizeAddress(word)
+	w = strings.Map(func(r rune) rune {
+		if r < 'a' || r > 'z' {
+			return -1
+		}
+		return r
+	}, w)
+
+	// The stem w[:n] is spelled out; letters of the ending still soften
+	// or denasalize those before them.
+	n, tail := len(w), ""
+	for _, e := range phoneticEndings {
+		if stem, ok := strings.CutSuffix(w, e.from); ok && len(stem) >= 2 {
+			n, tail = len(stem), e.to
+			break
+		}
+	}
+
+	var key []byte
+	emit := func(s string) {
+		for i := 0; i < len(s); i++ {
+			if len(key) == 0 || key[len(key)-1] != s[i] {
+				key = append(key, s[i])
+			}
+		}
+	}
+	for i := 0; i < n; {
+		matched := false
+		for _, r := range phoneticRules {
+			if !strings.HasPrefix(w[i:n], r.from) {
+				continue
+			}
+			j := i + len(r.from)
+			switch {
+			case r.next == "^":
+				if j < len(w) && (isVowel(w[j]) || w[j] == 'n' || w[j] == 'm') {
+					continue
+				}
+			case r.next != "":
+				if j >= len(w) || strings.IndexByte(r.next, w[j]) < 0 {
+					continue
+				}
+			}
+			emit(r.to)
+			i, matched = j, true
+			break
+		}
+		if matched {
+			continue
+		}
+		switch c := w[i]; {
+		case c == 's' && i > 0 && i+1 < len(w) && isVowel(w[i-1]) && isVowel(w[i+1]):
+			emit("z") // a single s between vowels
+		case c == 'e':
+			// A mute e, unless it closes a syllable before two consonants.
+			if i+2 < len(w) && !isVowel(w[i+1]) && !isVowel(w[i+2]) && w[i+1] != 'h' {
+				emit("E")
+			}
+		default:
+			emit(string(c))
+		}
+		i++
+	}
+	emit(tail)
+	return string(key)
+}
+
+// PhoneticLookup returns the words of the default dictionary sounding like
+// word (see Dictionary.PhoneticLookup).
+func PhoneticLookup(word string) []string {
+	return DefaultDictionary().PhoneticLookup(word)
+}
+
+// PhoneticLookup returns the words of d sharing the PhoneticKey of word,
+// word itself included if it is in d, closest spelling first (see
+// SuggestWord), then in alphabetical order.
+func (d *Dictionary) PhoneticLookup(word string) []string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	out := slices.Clone(d.phoneticIndex()[PhoneticKey(word)])
+	slices.SortFunc(out, func(a, b string) int {
+		if c := cmp.Compare(keyboardDistance(word, a), keyboardDistance(word, b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return out
+}
+
+// phoneticIndex returns the words of d by PhoneticKey, built on first use.
+func (d *Dictionary) phoneticIndex() map[string][]string {
+	d.phoneticOnce.Do(func() {
+		d.phonetic = make(map[string][]string)
+		for _, w := range d.words {
+			k := PhoneticKey(w)
+			d.phonetic[k] = append(d.phonetic[k], w)
+		}
+	})
+	return d.phonetic
+}
+
+// PhoneticGroup is a set of dictionary words sharing a phonetic key.
+type PhoneticGroup struct {
+	Key   string   `json:"key"`
+	Words []string `json:"words"`
+}
+
+// PhoneticGroups returns the groups of at least two words of d sharing a
+// PhoneticKey, largest first, then by key: the words a listener may confuse
+// when an address is dictated.
+func (d *Dictionary) PhoneticGroups() []PhoneticGroup {
+	var groups []PhoneticGroup
+	for k, words := range d.phoneticIndex() {
+		if len(words) > 1 {
+			groups = append(groups, PhoneticGroup{Key: k, Words: slices.Sorted(slices.Values(words))})
+		}
+	}
+	slices.SortFunc(groups, func(a, b PhoneticGroup) int {
+		if c := cmp.Compare(len(b.Words), len(a.Words)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return groups
+}
+
+// AmbiguousWordError reports a word of an address read by PhoneticAddress
+// that sounds like several dictionary words. It unwraps to an *AddressError
+// with ReasonUnknownWord for that word.
+type AmbiguousWordError struct {
+	AddressError
+	// Candidates are the valid addresses obtained by replacing the word by
+	// each of its sound-alikes, closest spelling first.
+	Candidates []Address
+}
+
+func (e *AmbiguousWordError) Error() string {
+	msg := fmt.Sprintf("q3m: ambiguous word %q (position %d)", e.Token, e.Position)
+	if len(e.Candidates) == 0 {
+		return msg
+	}
+	s := make([]string, len(e.Candidates))
+	for i, a := range e.Candidates {
+		s[i] = a.String()
+	}
+	return msg + " could be read as " + strings.Join(s, ", ")
+}
+
+// Unwrap returns the *AddressError of the word.
+func (e *AmbiguousWordError) Unwrap() error {
+	return &e.AddressError
+}
+
+// PhoneticAddress reads address, with DefaultCodec, as dictated (see
+// Codec.PhoneticAddress).
+func PhoneticAddress(address string) (Address, error) {
+	return defaultCodec().PhoneticAddress(address)
+}
+
+// PhoneticAddress reads address as dictated: each word missing from the
+// dictionary of c is replaced by the only dictionary word sounding alike,
+// so that "provinse.chootons.retiront" reads as
+// "province.shootons.retirons". Address is normalized first as by Decode.
+// Words without a sound-alike are left as they are, and reported as
+// unknown. A word with several sound-alikes is not guessed: since
+// neighbouring readings lie far apart, the error is an
+// *AmbiguousWordError listing the valid addresses it could stand for.
+// Other errors are the same as ParseAddress.
+func (c *Codec) PhoneticAddress(address string) (Address, error) {
+	head, words, res := splitAddress(normalizeAddress(address))
+	ambiguous, alikes := -1, []string(nil)
+	for i, w := range words {
+		if _, ok := c.dict.Index(w); ok {
+			continue
+		}
+		switch alike := c.dict.PhoneticLookup(w); {
+		case len(alike) == 1:
+			words[i] = alike[0]
+		case len(alike) > 1 && ambiguous < 0:
+			ambiguous, alikes = i, alike
+		}
+	}
+	if ambiguous >= 0 {
+		e := &AmbiguousWordError{AddressError: AddressError{
+			Address:  address,
+			Position: ambiguous + 1,
+			Token:    words[ambiguous],
+			Reason:   ReasonUnknownWord,
+		}}
+		for _, w := range alikes {
+			words[ambiguous] = w
+			if a, err := c.ParseAddress(head + strings.Join(words, ".") + res); err == nil {
+				e.Candidates = append(e.Candidates, a)
+			}
+		}
+		return Address{}, e
+	}
+	a, err := c.ParseAddress(head + strings.Join(words, ".") + res)
+	var ae *AddressError
+	if errors.As(err, &ae) {
+		ae.Address = address
+	}
+	return a, err
+}
@@ -0,0 +1,98 @@
+package q3m
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPhoneticKey(t *testing.T) {
+	groups := [][]string{
+		{"abattais", "abattait", "abattaient"},
+		{"abatte", "abattes", "abattent"},
+		{"chaussons", "shosson", "Chaussons"},
+		{"province", "provinse", "provinçe"},
+		{"shootons", "chootons"},
+		{"retirons", "retiront"},
+	}
+	for _, g := range groups {
+		for _, w := range g[1:] {
+			if PhoneticKey(w) != PhoneticKey(g[0]) {
+				t.Errorf("PhoneticKey(%q) = %q, want %q as %q", w, PhoneticKey(w), PhoneticKey(g[0]), g[0])
+			}
+		}
+	}
+	if PhoneticKey("abattez") == PhoneticKey("abattais") {
+		t.Error("abattez and abattais should not share a key")
+	}
+}
+
+func TestPhoneticLookup(t *testing.T) {
+	if got := PhoneticLookup("abattais"); !slices.Equal(got, []string{"abattais", "abattait"}) {
+		t.Errorf("PhoneticLookup(abattais) = %v", got)
+	}
+	if got := PhoneticLookup("shosson"); !slices.Equal(got, []string{"chausson"}) {
+		t.Errorf("PhoneticLookup(shosson) = %v", got)
+	}
+	if got := PhoneticLookup("zzzqx"); len(got) != 0 {
+		t.Errorf("PhoneticLookup(zzzqx) = %v, want none", got)
+	}
+}
+
+func TestPhoneticGroups(t *testing.T) {
+	groups := DefaultDictionary().PhoneticGroups()
+	if len(groups) == 0 {
+		t.Fatal("no phonetic groups")
+	}
+	seen := make(map[string]bool)
+	for i, g := range groups {
+		if len(g.Words) < 2 {
+			t.Errorf("group %q has %d words", g.Key, len(g.Words))
+		}
+		if i > 0 && len(g.Words) > len(groups[i-1].Words) {
+			t.Errorf("group %q larger than the previous one", g.Key)
+		}
+		for _, w := range g.Words {
+			if seen[w] {
+				t.Errorf("%q in two groups", w)
+			}
+			seen[w] = true
+			if PhoneticKey(w) != g.Key {
+				t.Errorf("PhoneticKey(%q) = %q, in group %q", w, PhoneticKey(w), g.Key)
+			}
+		}
+	}
+}
+
+func TestPhoneticAddress(t *testing.T) {
+	for _, s := range []string{
+		"provinse.chootons.retiront",
+		"Provinse Chootons Retirons",
+		"province.shootons.retirons",
+	} {
+		a, err := PhoneticAddress(s)
+		if err != nil || a.String() != "province.shootons.retirons" {
+			t.Errorf("PhoneticAddress(%q) = %v, %v", s, a, err)
+		}
+	}
+
+	// "zonne" sounds like zone and zones, hundreds of kilometres apart.
+	_, err := PhoneticAddress("province.shootons.zonne")
+	var ambiguous *AmbiguousWordError
+	if !errors.As(err, &ambiguous) || !errors.Is(err, ErrUnknownWord) || ambiguous.Position != 3 || ambiguous.Token != "zonne" {
+		t.Fatalf("PhoneticAddress(ambiguous) error = %v", err)
+	}
+	var got []string
+	for _, a := range ambiguous.Candidates {
+		got = append(got, a.String())
+	}
+	if !slices.Equal(got, []string{"province.shootons.zone", "province.shootons.zones"}) {
+		t.Errorf("candidates = %q", got)
+	}
+
+	var ae *AddressError
+	if _, err := PhoneticAddress("zzzqx.chootons.retirons"); !errors.As(err, &ae) ||
+		ae.Reason != ReasonUnknownWord || ae.Token != "zzzqx" || ae.Address != "zzzqx.chootons.retirons" {
+		t.Errorf("PhoneticAddress(unknown word) error = %v", err)
+	}
+}
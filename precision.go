@@ -0,0 +1,129 @@
+package q3m
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Precision is the size of the sub-cell designated by the optional fourth
+// word of a 1m address, e.g. "w1.w2.w3.w4". The fourth word numbers the
+// sub-cells of the 1m cell row by row from its south-west corner: the first
+// 10,000 words of the dictionary the 100 x 100 sub-cells of 1cm, the next
+// 100 words the 10 x 10 sub-cells of 10cm. The zero value is Precision1m,
+// a plain three-word address.
+type Precision uint8
+
+const (
+	Precision1m Precision = iota
+	Precision10cm
+	Precision1cm
+
+	numPrecisions = iota
+)
+
+var precisionNames = [numPrecisions]string{"1m", "10cm", "1cm"}
+
+// subDivisions is the number of sub-cells per side of a 1m cell at each
+// precision.
+var subDivisions = [numPrecisions]uint64{1, 10, 100}
+
+// Sub-cell word ranges: 1cm sub-cells come first, then 10cm ones.
+const (
+	subWords1cm  = 100 * 100
+	subWords10cm = 10 * 10
+	subWords     = subWords1cm + subWords10cm
+)
+
+// ParsePrecision parses "1m", "10cm" or "1cm".
+func ParsePrecision(s string) (Precision, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for p, name := range precisionNames {
+		if s == name {
+			return Precision(p), nil
+		}
+	}
+	return 0, fmt.Errorf("q3m: unknown precision %q (1m, 10cm or 1cm)", s)
+}
+
+// Valid reports whether p is one of the defined precisions.
+func (p Precision) Valid() bool {
+	return p < numPrecisions
+}
+
+// Size returns the side of the sub-cells of p, in metres.
+func (p Precision) Size() float64 {
+	return 1 / float64(subDivisions[p])
+}
+
+func (p Precision) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Precision(%d)", uint8(p))
+	}
+	return precisionNames[p]
+}
+
+// MarshalText encodes p as its name, e.g. "1cm".
+func (p Precision) MarshalText() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("q3m: invalid precision %d", uint8(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a name accepted by ParsePrecision.
+func (p *Precision) UnmarshalText(b []byte) error {
+	v, err := ParsePrecision(string(b))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// EncodePrecise converts WGS84 coordinates to the address of their sub-cell
+// of precision p. Errors are the same as Encode.
+func EncodePrecise(lat, lon float64, p Precision) (Address, error) {
+	return defaultCodec().EncodePrecise(lat, lon, p)
+}
+
+// DecodePrecise is Decode, also reporting the precision of the address:
+// Precision1m for three words, the sub-cell size for four.
+func DecodePrecise(address string) (Coordinate, Precision, error) {
+	return defaultCodec().DecodePrecise(address)
+}
+
+// subCell returns the precision and the column and row within its 1m cell
+// of the sub-cell numbered by the dictionary index i, or false if i numbers
+// no sub-cell.
+func subCell(i int) (p Precision, x, y uint64, ok bool) {
+	switch {
+	case i < 0 || i >= subWords:
+		return 0, 0, 0, false
+	case i < subWords1cm:
+		p = Precision1cm
+	default:
+		p, i = Precision10cm, i-subWords1cm
+	}
+	k := subDivisions[p]
+	return p, uint64(i) % k, uint64(i) / k, true
+}
+
+// subIndex inverts subCell.
+func subIndex(p Precision, x, y uint64) int {
+	i := int(y*subDivisions[p] + x)
+	if p == Precision10cm {
+		i += subWords1cm
+	}
+	return i
+}
+
+// subOffset returns the position of the sub-cell of precision p containing
+// the point at offset (dx, dy) from the south-west corner of its 1m cell.
+func subOffset(p Precision, dx, dy float64) (x, y uint64) {
+	k := subDivisions[p]
+	// Clamp against rounding at the north and east edges.
+	x = min(uint64(max(math.Floor(dx*float64(k)), 0)), k-1)
+	y = min(uint64(max(math.Floor(dy*float64(k)), 0)), k-1)
+	return x, y
+}
@@ -0,0 +1,95 @@
+package q3m
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParsePrecision(t *testing.T) {
+	for p := range Precision(numPrecisions) {
+		got, err := ParsePrecision(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParsePrecision(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParsePrecision("1mm"); err == nil {
+		t.Error("ParsePrecision(1mm) succeeded")
+	}
+}
+
+func TestSubCellIndex(t *testing.T) {
+	for i := range subWords {
+		p, x, y, ok := subCell(i)
+		if !ok || subIndex(p, x, y) != i {
+			t.Fatalf("subCell(%d) = %v, %d, %d, %v", i, p, x, y, ok)
+		}
+	}
+	if _, _, _, ok := subCell(subWords); ok {
+		t.Errorf("subCell(%d) succeeded", subWords)
+	}
+}
+
+func TestEncodePreciseRoundTrip(t *testing.T) {
+	e0, n0 := ToLambert93(eiffel.Lat, eiffel.Lon)
+	for _, p := range []Precision{Precision1m, Precision10cm, Precision1cm} {
+		addr, err := EncodePrecise(eiffel.Lat, eiffel.Lon, p)
+		if err != nil {
+			t.Fatalf("EncodePrecise(%s): %v", p, err)
+		}
+		if addr.W1 != "province" || addr.W2 != "shootons" || addr.W3 != "retirons" {
+			t.Errorf("EncodePrecise(%s) = %s, want the v1 address first", p, addr)
+		}
+		if (p == Precision1m) != (addr.W4 == "") {
+			t.Errorf("EncodePrecise(%s) = %s", p, addr)
+		}
+
+		coord, got, err := DecodePrecise(addr.String())
+		if err != nil || got != p {
+			t.Fatalf("DecodePrecise(%q) = %v, %v, want %s", addr, got, err, p)
+		}
+		e1, n1 := ToLambert93(coord.Lat, coord.Lon)
+		if half := p.Size() / 2; math.Abs(e1-e0) > half+1e-6 || math.Abs(n1-n0) > half+1e-6 {
+			t.Errorf("DecodePrecise(%q) is (%.4f, %.4f) m from the input", addr, e1-e0, n1-n0)
+		}
+
+		back, err := ParseAddress(addr.String())
+		if err != nil || back != addr {
+			t.Errorf("ParseAddress(%q) = %+v, %v", addr, back, err)
+		}
+	}
+}
+
+func TestDecodePreciseErrors(t *testing.T) {
+	tests := []struct {
+		address string
+		want    error
+	}{
+		{"province.shootons.retirons." + WordAt(subWords), ErrInvalidCell},
+		{"province.shootons.retirons.zzzz", ErrUnknownWord},
+		{"province.shootons.retirons.province@10m", ErrInvalidFormat},
+		{"province.shootons.retirons.province.province", ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		if _, _, err := DecodePrecise(tt.address); !errors.Is(err, tt.want) {
+			t.Errorf("DecodePrecise(%q): err = %v, want %v", tt.address, err, tt.want)
+		}
+	}
+	var ae *AddressError
+	if _, err := Decode("province.shootons.retirons.zzzz"); !errors.As(err, &ae) || ae.Position != 4 {
+		t.Errorf("unknown fourth word: err = %v", err)
+	}
+}
+
+func TestEncodePreciseSmallDictionary(t *testing.T) {
+	c, err := NewCodec(WithDictionary(tinyDictionary(t, 22)), WithGrid(eiffelBounds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EncodePrecise(eiffel.Lat, eiffel.Lon, Precision1cm); err == nil {
+		t.Error("EncodePrecise succeeded without sub-cell words")
+	}
+	if _, err := c.EncodePrecise(eiffel.Lat, eiffel.Lon, Precision1m); err != nil {
+		t.Errorf("EncodePrecise(1m): %v", err)
+	}
+}
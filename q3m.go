@@ -1,79 +1,80 @@
 package q3m
 
-import (
-	"fmt"
-	"strings"
-)
-
 // Coordinate represents a WGS84 position.
 type Coordinate struct {
 	Lat float64 `json:"lat"`
 	Lon float64 `json:"lon"`
 }
 
-// Address represents a q3m three-word address.
+// Address represents a q3m address: three words at the default 1m
+// resolution, fewer for coarse resolutions (W3, then W2, left empty), and
+// an optional fourth word W4 designating a sub-cell (see Precision).
 type Address struct {
 	W1 string `json:"w1"`
-	W2 string `json:"w2"`
-	W3 string `json:"w3"`
+	W2 string `json:"w2,omitempty"`
+	W3 string `json:"w3,omitempty"`
+	W4 string `json:"w4,omitempty"`
+
+	// Region is the code of the overseas region of the address, empty in
+	// metropolitan France.
+	Region string `json:"region,omitempty"`
+
+	// Private marks an address of a codec keyed with WithSecret or WithFF1.
+	Private bool `json:"private,omitempty"`
+	// Resolution is the size of the designated cell.
+	Resolution Resolution `json:"resolution,omitempty"`
 }
 
-// String returns the dotted representation "w1.w2.w3".
+// PrivateMarker prefixes private addresses, so that they cannot be mistaken
+// for public ones.
+const PrivateMarker = "~"
+
+// String returns the dotted representation "w1.w2.w3" (or "w1.w2.w3.w4"),
+// prefixed with the region code and RegionMarker for an overseas address,
+// then with PrivateMarker for a private one, and followed by
+// ResolutionMarker and the resolution for a coarse one.
 func (a Address) String() string {
-	return a.W1 + "." + a.W2 + "." + a.W3
+	s := a.W1
+	for _, w := range []string{a.W2, a.W3, a.W4} {
+		if w != "" {
+			s += "." + w
+		}
+	}
+	if a.Region != "" {
+		s = a.Region + RegionMarker + s
+	}
+	if a.Private {
+		s = PrivateMarker + s
+	}
+	if a.Resolution != Res1m {
+		s += ResolutionMarker + a.Resolution.String()
+	}
+	return s
 }
 
 // w is the dictionary size, used for base conversion.
 const w = uint64(DictSize)
 
 // Encode converts WGS84 coordinates to a q3m three-word address.
+// The error is an *OutOfGridError wrapping ErrOutOfGrid.
 func Encode(lat, lon float64) (Address, error) {
-	e, n := ToLambert93(lat, lon)
-
-	idx, ok := CellIndex(e, n)
-	if !ok {
-		return Address{}, fmt.Errorf("q3m: coordinates (%f, %f) are outside the Lambert93 grid", lat, lon)
-	}
-
-	shuffled := Shuffle(idx)
-
-	w1 := int(shuffled / (w * w))
-	w2 := int((shuffled / w) % w)
-	w3 := int(shuffled % w)
-
-	return Address{
-		W1: WordAt(w1),
-		W2: WordAt(w2),
-		W3: WordAt(w3),
-	}, nil
+	return defaultCodec().Encode(lat, lon)
 }
 
-// Decode converts a q3m three-word address (dot-separated) back to WGS84 coordinates.
-// The returned coordinate is the centre of the 1m x 1m cell.
+// Decode converts a q3m address (dot-separated) back to WGS84 coordinates.
+// The returned coordinate is the centre of the cell: 1m x 1m, coarser for
+// an address with a resolution marker, finer for a four-word address.
+// Variants typed or pasted by users, such as "Province Shootons Retirons"
+// or "///province.shootons.retirons", are accepted: see NormalizeAddress.
+//
+// Errors are *AddressError values wrapping ErrInvalidFormat, ErrUnknownWord
+// or ErrInvalidCell.
 func Decode(address string) (Coordinate, error) {
-	parts := strings.Split(strings.ToLower(strings.TrimSpace(address)), ".")
-	if len(parts) != 3 {
-		return Coordinate{}, fmt.Errorf("q3m: invalid address format %q (expected w1.w2.w3)", address)
-	}
-
-	var indices [3]uint64
-	for i, p := range parts {
-		idx, ok := IndexOf(p)
-		if !ok {
-			return Coordinate{}, fmt.Errorf("q3m: unknown word %q", p)
-		}
-		indices[i] = uint64(idx)
-	}
-
-	shuffled := indices[0]*w*w + indices[1]*w + indices[2]
-	idx := Unshuffle(shuffled)
-
-	if idx >= TotalCells {
-		return Coordinate{}, fmt.Errorf("q3m: address %q maps to invalid cell index", address)
-	}
-
-	e, n := CellCenter(idx)
-	lat, lon := FromLambert93(e, n)
+	return defaultCodec().Decode(address)
+}
 
-	return Coordinate{Lat: lat, Lon: lon}, nil
+// ParseAddress normalizes address as by NormalizeAddress, validates it and
+// returns it in canonical form. Errors are the same as Decode.
+func ParseAddress(address string) (Address, error) {
+	return defaultCodec().ParseAddress(address)
 }
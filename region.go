@@ -0,0 +1,155 @@
+package q3m
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// RegionMarker separates the code of an overseas region from the words of
+// its addresses, e.g. "gp:w1.w2.w3". Metropolitan addresses carry no region.
+const RegionMarker = ":"
+
+// Region is an overseas department addressed on its own grid of 1m cells,
+// laid out in the UTM zone of its official projection. Each region has its
+// own permutation, so the same words designate unrelated cells in two
+// regions: the region code tells them apart.
+type Region struct {
+	Code  string // address prefix, e.g. "gp"
+	Name  string
+	CRS   string // official projected CRS
+	EPSG  int
+	Zone  int  // UTM zone
+	South bool // southern hemisphere
+
+	// Bounds is the extent of the grid in UTM metres. IMMUTABLE: changing
+	// it reassigns every address of the region.
+	Bounds Bounds
+
+	// latMin, latMax, lonMin, lonMax delimit the WGS84 box of points
+	// encoded in the region; the grid extends slightly beyond it.
+	latMin, latMax, lonMin, lonMax float64
+}
+
+// regions are the overseas departments, in the order of their cell region
+// numbers. IMMUTABLE.
+var regions = []Region{
+	{
+		Code: "gp", Name: "Guadeloupe", CRS: "RGAF09 / UTM 20N", EPSG: 5490, Zone: 20,
+		Bounds: Bounds{EMin: 617000, NMin: 1747000, EMax: 720000, NMax: 1831000},
+		latMin: 15.80, latMax: 16.55, lonMin: -61.90, lonMax: -60.95,
+	},
+	{
+		Code: "mq", Name: "Martinique", CRS: "RGAF09 / UTM 20N", EPSG: 5490, Zone: 20,
+		Bounds: Bounds{EMin: 688000, NMin: 1587000, EMax: 738000, NMax: 1649000},
+		latMin: 14.35, latMax: 14.90, lonMin: -61.25, lonMax: -60.80,
+	},
+	{
+		Code: "gf", Name: "Guyane", CRS: "RGFG95 / UTM 22N", EPSG: 2972, Zone: 22,
+		Bounds: Bounds{EMin: 93000, NMin: 232000, EMax: 434000, NMax: 643000},
+		latMin: 2.10, latMax: 5.80, lonMin: -54.65, lonMax: -51.60,
+	},
+	{
+		Code: "re", Name: "La Réunion", CRS: "RGR92 / UTM 40S", EPSG: 2975, Zone: 40, South: true,
+		Bounds: Bounds{EMin: 312000, NMin: 7632000, EMax: 381000, NMax: 7695000},
+		latMin: -21.40, latMax: -20.85, lonMin: 55.20, lonMax: 55.85,
+	},
+	{
+		Code: "yt", Name: "Mayotte", CRS: "RGM04 / UTM 38S", EPSG: 4471, Zone: 38, South: true,
+		Bounds: Bounds{EMin: 494000, NMin: 8557000, EMax: 539000, NMax: 8608000},
+		latMin: -13.05, latMax: -12.60, lonMin: 44.95, lonMax: 45.35,
+	},
+}
+
+// Regions returns the overseas regions addressed by DefaultCodec.
+func Regions() []Region {
+	return append([]Region(nil), regions...)
+}
+
+// RegionOf returns the overseas region encoding the WGS84 point (lat, lon),
+// or false for metropolitan France and points outside every region.
+func RegionOf(lat, lon float64) (Region, bool) {
+	for _, r := range regions {
+		if r.contains(lat, lon) {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// contains reports whether (lat, lon) is encoded in r.
+func (r Region) contains(lat, lon float64) bool {
+	return lat >= r.latMin && lat <= r.latMax && lon >= r.lonMin && lon <= r.lonMax
+}
+
+// key returns the Feistel key of the grids of r, derived from the v1 key
+// and the region code.
+func (r Region) key() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(r.Code))
+	return feistelKey ^ h.Sum64()
+}
+
+// newRegionCodec returns the codec of the i-th region with dictionary dict.
+func newRegionCodec(i int, dict *Dictionary) *Codec {
+	r := &regions[i]
+	c := &Codec{dict: dict, proj: utmZone{zone: r.Zone, south: r.South}, region: r, reg: uint8(i + 1)}
+	g, err := newGrid(r.Bounds)
+	if err != nil {
+		panic(err)
+	}
+	n := uint64(dict.Len())
+	for res := range Resolution(numResolutions) {
+		rg, ok := g.coarsen(res.Size())
+		if !ok {
+			panic("q3m: region " + r.Code + " bounds are not whole kilometres")
+		}
+		c.levels[res] = newLevel(rg, newFeistel(r.key(), rg.total()), n)
+	}
+	return c
+}
+
+// regionAt returns the codec of the overseas region of c encoding (lat,
+// lon), or nil.
+func (c *Codec) regionAt(lat, lon float64) *Codec {
+	for _, rc := range c.regions {
+		if rc.region.contains(lat, lon) {
+			return rc
+		}
+	}
+	return nil
+}
+
+// codecFor returns the codec decoding address: c, or the codec of the
+// overseas region named by its prefix. An unknown region is reported as
+// ReasonInvalidFormat.
+func (c *Codec) codecFor(address string) (*Codec, error) {
+	body := strings.TrimPrefix(normalizeAddress(address), PrivateMarker)
+	code, _, ok := strings.Cut(body, RegionMarker)
+	if !ok {
+		return c, nil
+	}
+	for _, rc := range c.regions {
+		if rc.region.Code == code {
+			return rc, nil
+		}
+	}
+	return nil, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+}
+
+// regionCode returns the code of the region of c, empty for metropolitan
+// and custom grids.
+func (c *Codec) regionCode() string {
+	if c.region == nil {
+		return ""
+	}
+	return c.region.Code
+}
+
+// Region returns the overseas region of c, or false for a metropolitan
+// cell.
+func (c Cell) Region() (Region, bool) {
+	if c.reg == 0 {
+		return Region{}, false
+	}
+	return regions[c.reg-1], true
+}
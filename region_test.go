@@ -0,0 +1,165 @@
+package q3m
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// One point in each overseas region.
+var regionPoints = []struct {
+	code     string
+	lat, lon float64
+}{
+	{"gp", 16.2411, -61.5331}, // Pointe-à-Pitre
+	{"mq", 14.6037, -61.0731}, // Fort-de-France
+	{"gf", 4.9372, -52.3260},  // Cayenne
+	{"re", -20.8789, 55.4481}, // Saint-Denis
+	{"yt", -12.7806, 45.2279}, // Mamoudzou
+}
+
+func TestToUTM(t *testing.T) {
+	// CN Tower, Toronto, in UTM zone 17N.
+	e, n := ToUTM(43.642567, -79.387139, 17, false)
+	if math.Abs(e-630084) > 1 || math.Abs(n-4833438) > 1 {
+		t.Errorf("ToUTM(CN Tower) = (%f, %f), want ~(630084, 4833438)", e, n)
+	}
+	for _, p := range regionPoints {
+		r, _ := RegionOf(p.lat, p.lon)
+		e, n := ToUTM(p.lat, p.lon, r.Zone, r.South)
+		lat, lon := FromUTM(e, n, r.Zone, r.South)
+		if math.Abs(lat-p.lat) > 1e-8 || math.Abs(lon-p.lon) > 1e-8 {
+			t.Errorf("%s: UTM round trip gives (%f, %f)", p.code, lat, lon)
+		}
+	}
+}
+
+func TestRegionGridsContainBoxes(t *testing.T) {
+	for _, r := range regions {
+		for _, lat := range []float64{r.latMin, r.latMax} {
+			for _, lon := range []float64{r.lonMin, r.lonMax} {
+				e, n := ToUTM(lat, lon, r.Zone, r.South)
+				b := r.Bounds
+				if e < b.EMin || e >= b.EMax || n < b.NMin || n >= b.NMax {
+					t.Errorf("%s: corner (%v, %v) at (%.0f, %.0f) outside the grid", r.Code, lat, lon, e, n)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeRegion(t *testing.T) {
+	for _, p := range regionPoints {
+		r, ok := RegionOf(p.lat, p.lon)
+		if !ok || r.Code != p.code {
+			t.Fatalf("RegionOf(%s) = %v, %v", p.code, r.Code, ok)
+		}
+		addr, err := Encode(p.lat, p.lon)
+		if err != nil {
+			t.Fatalf("Encode(%s): %v", p.code, err)
+		}
+		if addr.Region != p.code || !strings.HasPrefix(addr.String(), p.code+RegionMarker) {
+			t.Errorf("Encode(%s) = %s", p.code, addr)
+		}
+
+		coord, err := Decode(addr.String())
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", addr, err)
+		}
+		e0, n0 := ToUTM(p.lat, p.lon, r.Zone, r.South)
+		e1, n1 := ToUTM(coord.Lat, coord.Lon, r.Zone, r.South)
+		if math.Abs(e1-e0) > 0.5+1e-6 || math.Abs(n1-n0) > 0.5+1e-6 {
+			t.Errorf("Decode(%q) is (%.3f, %.3f) m from the input", addr, e1-e0, n1-n0)
+		}
+
+		back, err := ParseAddress(strings.ToUpper(addr.String()))
+		if err != nil || back != addr {
+			t.Errorf("ParseAddress(%q) = %+v, %v", addr, back, err)
+		}
+
+		// The same words designate another cell without the region.
+		metro := addr
+		metro.Region = ""
+		if c, err := Decode(metro.String()); err == nil && math.Abs(c.Lat-coord.Lat) < 1 {
+			t.Errorf("Decode(%q) = %v, close to %s", metro, c, addr)
+		}
+	}
+}
+
+func TestEncodeRegionPrecise(t *testing.T) {
+	p := regionPoints[3]
+	addr, err := EncodePrecise(p.lat, p.lon, Precision1cm)
+	if err != nil || addr.Region != p.code || addr.W4 == "" {
+		t.Fatalf("EncodePrecise = %s, %v", addr, err)
+	}
+	if _, got, err := DecodePrecise(addr.String()); err != nil || got != Precision1cm {
+		t.Errorf("DecodePrecise(%q) = %v, %v", addr, got, err)
+	}
+}
+
+func TestRegionMetropolitanUnchanged(t *testing.T) {
+	addr, err := Encode(eiffel.Lat, eiffel.Lon)
+	if err != nil || addr.String() != "province.shootons.retirons" {
+		t.Errorf("Encode(eiffel) = %s, %v", addr, err)
+	}
+	if _, ok := RegionOf(eiffel.Lat, eiffel.Lon); ok {
+		t.Error("RegionOf(eiffel) succeeded")
+	}
+}
+
+func TestDecodeRegionErrors(t *testing.T) {
+	for _, address := range []string{
+		"xx:province.shootons.retirons",
+		":province.shootons.retirons",
+		"gp:gp:province.shootons.retirons",
+	} {
+		if _, err := Decode(address); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Decode(%q): err = %v, want ErrInvalidFormat", address, err)
+		}
+	}
+
+	c, err := NewCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Decode("gp:province.shootons.retirons"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("custom codec decoded a regional address: %v", err)
+	}
+}
+
+func TestRegionCell(t *testing.T) {
+	p := regionPoints[0]
+	cell, err := CellOf(p.lat, p.lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := cell.Region(); !ok || r.Code != p.code {
+		t.Errorf("Region() = %v, %v", r.Code, ok)
+	}
+	addr, _ := Encode(p.lat, p.lon)
+	if cell.Address() != addr {
+		t.Errorf("Address() = %s, want %s", cell.Address(), addr)
+	}
+	if back, err := addr.Cell(); err != nil || back != cell {
+		t.Errorf("Cell() = %v, %v", back, err)
+	}
+
+	km, ok := cell.Ancestor(Res1km)
+	if !ok || km.Address().Region != p.code {
+		t.Fatalf("Ancestor(1km) = %v", km.Address())
+	}
+	if want, _ := EncodeAt(p.lat, p.lon, Res1km); km.Address() != want {
+		t.Errorf("Ancestor(1km) = %s, want %s", km.Address(), want)
+	}
+	for _, child := range km.Children()[:3] {
+		if back, ok := child.Parent(); !ok || back != km {
+			t.Errorf("Parent() of %s = %s", child.Address(), back.Address())
+		}
+	}
+
+	n, ok := cell.offset(1, 0)
+	if !ok || n.reg != cell.reg || n.Bounds().EMin != cell.Bounds().EMax {
+		t.Errorf("offset(1, 0) = %+v", n)
+	}
+}
@@ -0,0 +1,140 @@
+package q3m
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resolution is the size of the cells an address designates. Coarser cells
+// aggregate 10 x 10 cells of the level below, down to the 1m cells of the
+// grid; their addresses are followed by ResolutionMarker and the resolution,
+// e.g. "w1.w2@1km". The zero value is Res1m, the v1 resolution.
+type Resolution uint8
+
+const (
+	Res1m Resolution = iota
+	Res10m
+	Res100m
+	Res1km
+
+	numResolutions = iota
+)
+
+// ResolutionMarker separates the words of a coarse address from its
+// resolution.
+const ResolutionMarker = "@"
+
+var resolutionNames = [numResolutions]string{"1m", "10m", "100m", "1km"}
+
+// Resolutions returns every resolution, finest first.
+func Resolutions() []Resolution {
+	return []Resolution{Res1m, Res10m, Res100m, Res1km}
+}
+
+// ParseResolution parses "1m", "10m", "100m" or "1km" ("1000m" is accepted).
+func ParseResolution(s string) (Resolution, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "1000m" {
+		return Res1km, nil
+	}
+	for r, name := range resolutionNames {
+		if s == name {
+			return Resolution(r), nil
+		}
+	}
+	return 0, fmt.Errorf("q3m: unknown resolution %q (1m, 10m, 100m or 1km)", s)
+}
+
+// Valid reports whether r is one of the defined resolutions.
+func (r Resolution) Valid() bool {
+	return r < numResolutions
+}
+
+// Size returns the side of the cells of r, in metres.
+func (r Resolution) Size() uint64 {
+	s := uint64(1)
+	for range r {
+		s *= 10
+	}
+	return s
+}
+
+func (r Resolution) String() string {
+	if !r.Valid() {
+		return fmt.Sprintf("Resolution(%d)", uint8(r))
+	}
+	return resolutionNames[r]
+}
+
+// MarshalText encodes r as its name, e.g. "100m".
+func (r Resolution) MarshalText() ([]byte, error) {
+	if !r.Valid() {
+		return nil, fmt.Errorf("q3m: invalid resolution %d", uint8(r))
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a name accepted by ParseResolution.
+func (r *Resolution) UnmarshalText(b []byte) error {
+	v, err := ParseResolution(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// defaultGrids holds the metropolitan grid at every resolution: its extent
+// is a whole number of kilometres.
+var defaultGrids = func() (g [numResolutions]grid) {
+	for r := range g {
+		g[r], _ = defaultGrid.coarsen(Resolution(r).Size())
+	}
+	return g
+}()
+
+// EncodeAt converts WGS84 coordinates to the address of the cell of
+// resolution r containing them. Errors are the same as Encode.
+func EncodeAt(lat, lon float64, r Resolution) (Address, error) {
+	return defaultCodec().EncodeAt(lat, lon, r)
+}
+
+// Resolution returns the resolution of c.
+func (c Cell) Resolution() Resolution {
+	return c.res
+}
+
+// Parent returns the cell one level coarser containing c, or false if c is
+// already a 1km cell.
+func (c Cell) Parent() (Cell, bool) {
+	return c.Ancestor(c.res + 1)
+}
+
+// Ancestor returns the cell of resolution r containing c, or false if r is
+// finer than c or invalid. c.Ancestor(c.Resolution()) is c.
+func (c Cell) Ancestor(r Resolution) (Cell, bool) {
+	if r < c.res || !r.Valid() {
+		return Cell{}, false
+	}
+	f := r.Size() / c.res.Size()
+	g := Cell{res: r, reg: c.reg}.grid()
+	return Cell{idx: c.Row()/f*g.width + c.Col()/f, res: r, reg: c.reg}, true
+}
+
+// Children returns the 100 cells one level finer making up c, row by row
+// from the south-west corner, or nil if c is a 1m cell.
+func (c Cell) Children() []Cell {
+	if c.res == Res1m {
+		return nil
+	}
+	r := c.res - 1
+	g := Cell{res: r, reg: c.reg}.grid()
+	row, col := c.Row()*10, c.Col()*10
+	out := make([]Cell, 0, 100)
+	for y := range uint64(10) {
+		for x := range uint64(10) {
+			out = append(out, Cell{idx: (row+y)*g.width + col + x, res: r, reg: c.reg})
+		}
+	}
+	return out
+}
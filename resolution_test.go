@@ -0,0 +1,176 @@
+package q3m
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseResolution(t *testing.T) {
+	for _, r := range Resolutions() {
+		got, err := ParseResolution(r.String())
+		if err != nil || got != r {
+			t.Errorf("ParseResolution(%q) = %v, %v", r.String(), got, err)
+		}
+	}
+	if r, err := ParseResolution("1000m"); err != nil || r != Res1km {
+		t.Errorf("ParseResolution(1000m) = %v, %v", r, err)
+	}
+	if _, err := ParseResolution("5m"); err == nil {
+		t.Error("ParseResolution(5m) succeeded")
+	}
+	if Res100m.Size() != 100 || Res1km.Size() != 1000 {
+		t.Errorf("Size = %d, %d", Res100m.Size(), Res1km.Size())
+	}
+}
+
+func TestDefaultWordsPerResolution(t *testing.T) {
+	// 100m cells need three words: 11,500 x 10,700 cells exceed 10,800².
+	want := map[Resolution]int{Res1m: 3, Res10m: 3, Res100m: 3, Res1km: 2}
+	for r, n := range want {
+		if got := DefaultCodec().Words(r); got != n {
+			t.Errorf("Words(%s) = %d, want %d", r, got, n)
+		}
+	}
+}
+
+func TestEncodeAtRoundTrip(t *testing.T) {
+	for _, r := range Resolutions() {
+		addr, err := EncodeAt(eiffel.Lat, eiffel.Lon, r)
+		if err != nil {
+			t.Fatalf("EncodeAt(%s): %v", r, err)
+		}
+		s := addr.String()
+		if (r != Res1m) != strings.HasSuffix(s, ResolutionMarker+r.String()) {
+			t.Errorf("EncodeAt(%s) = %q, marker mismatch", r, s)
+		}
+		if got := strings.Count(strings.TrimSuffix(s, ResolutionMarker+r.String()), ".") + 1; got != DefaultCodec().Words(r) {
+			t.Errorf("EncodeAt(%s) = %q has %d words", r, s, got)
+		}
+
+		back, err := ParseAddress(s)
+		if err != nil || back != addr {
+			t.Errorf("ParseAddress(%q) = %+v, %v", s, back, err)
+		}
+
+		coord, err := Decode(s)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", s, err)
+		}
+		e0, n0 := ToLambert93(eiffel.Lat, eiffel.Lon)
+		e1, n1 := ToLambert93(coord.Lat, coord.Lon)
+		if half := float64(r.Size()) / 2; math.Abs(e1-e0) > half+1e-3 || math.Abs(n1-n0) > half+1e-3 {
+			t.Errorf("Decode(%q) is (%.1f, %.1f) m from the input", s, e1-e0, n1-n0)
+		}
+	}
+	if addr, _ := EncodeAt(eiffel.Lat, eiffel.Lon, Res1m); addr.String() != "province.shootons.retirons" {
+		t.Errorf("EncodeAt(1m) = %s, want the v1 address", addr)
+	}
+}
+
+func TestParentChildren(t *testing.T) {
+	c, err := CellOf(eiffel.Lat, eiffel.Lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range Resolutions()[1:] {
+		p, ok := c.Parent()
+		if !ok || p.Resolution() != r {
+			t.Fatalf("Parent of %s cell = %v, %v", c.Resolution(), p, ok)
+		}
+		want, _ := EncodeAt(eiffel.Lat, eiffel.Lon, r)
+		if p.Address() != want {
+			t.Errorf("Parent at %s = %s, want %s", r, p.Address(), want)
+		}
+		b := p.Bounds()
+		if b.EMax-b.EMin != float64(r.Size()) {
+			t.Errorf("%s cell is %.0f m wide", r, b.EMax-b.EMin)
+		}
+
+		children := p.Children()
+		if len(children) != 100 {
+			t.Fatalf("%s cell has %d children", r, len(children))
+		}
+		found := false
+		for _, ch := range children {
+			if ch == c {
+				found = true
+			}
+			if pp, _ := ch.Parent(); pp != p {
+				t.Fatalf("child %v of %v has parent %v", ch, p, pp)
+			}
+		}
+		if !found {
+			t.Errorf("%s cell does not list its child", r)
+		}
+		c = p
+	}
+	if _, ok := c.Parent(); ok {
+		t.Error("a 1km cell has no parent")
+	}
+	if c1m, _ := CellOf(eiffel.Lat, eiffel.Lon); c1m.Children() != nil {
+		t.Error("a 1m cell has no children")
+	}
+	if a, _ := CellOf(eiffel.Lat, eiffel.Lon); mustAncestor(t, a, Res1km) != c {
+		t.Error("Ancestor(1km) differs from the chain of parents")
+	}
+}
+
+func mustAncestor(t *testing.T, c Cell, r Resolution) Cell {
+	t.Helper()
+	a, ok := c.Ancestor(r)
+	if !ok {
+		t.Fatalf("Ancestor(%s) of %v failed", r, c)
+	}
+	return a
+}
+
+func TestCoarseNeighbors(t *testing.T) {
+	addr, _ := EncodeAt(eiffel.Lat, eiffel.Lon, Res1km)
+	nb, err := Neighbors(addr)
+	if err != nil {
+		t.Fatalf("Neighbors: %v", err)
+	}
+	if len(nb) != 8 {
+		t.Fatalf("got %d neighbours", len(nb))
+	}
+	for _, a := range nb {
+		if a.Resolution != Res1km || a.W3 != "" {
+			t.Errorf("neighbour %s is not a 1km address", a)
+		}
+	}
+}
+
+func TestDecodeResolutionErrors(t *testing.T) {
+	for _, s := range []string{
+		"province.shootons.retirons@5m",
+		"province.shootons.retirons@1km",
+		"province.shootons@100m",
+		"province.shootons",
+	} {
+		if _, err := Decode(s); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Decode(%q): err = %v, want ErrInvalidFormat", s, err)
+		}
+	}
+}
+
+func TestAddressJSONResolution(t *testing.T) {
+	addr, _ := EncodeAt(eiffel.Lat, eiffel.Lon, Res1km)
+	b, err := json.Marshal(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"resolution":"1km"`) || strings.Contains(string(b), "w3") {
+		t.Errorf("JSON = %s", b)
+	}
+	var back Address
+	if err := json.Unmarshal(b, &back); err != nil || back != addr {
+		t.Errorf("Unmarshal = %+v, %v", back, err)
+	}
+	b, _ = json.Marshal(Address{W1: "a", W2: "b", W3: "c"})
+	if strings.Contains(string(b), "resolution") {
+		t.Errorf("1m address JSON = %s, want no resolution", b)
+	}
+}
@@ -0,0 +1,154 @@
+package q3m
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Correction identifies how a Resolve candidate differs from its input.
+type Correction string
+
+const (
+	CorrectionNone      Correction = "none"      // the address as given
+	CorrectionSwap      Correction = "swap"      // the same words in another order
+	CorrectionHomophone Correction = "homophone" // one word replaced by a sound-alike
+	CorrectionSpelling  Correction = "spelling"  // one word replaced by a close spelling
+)
+
+// Likelihood costs of each correction kind; spelling edits use their
+// keyboard distance (see SuggestWord).
+const (
+	costSwap      = 1.5
+	costHomophone = 0.5
+)
+
+// Candidate is a plausible reading of a misheard address.
+type Candidate struct {
+	Address    Address    `json:"address"`
+	Coordinate Coordinate `json:"coordinate"`
+	Distance   float64    `json:"distance"` // metres from the reference point
+	Correction Correction `json:"correction"`
+	Position   int        `json:"position,omitempty"` // 1-based corrected word, 0 if none
+	Cost       float64    `json:"cost"`               // lower is more likely
+}
+
+// Resolve enumerates plausible corrections of address (the address itself,
+// swapped word orders, and one-word homophone or spelling edits) and keeps
+// those whose cell centre lies within radius metres of near. Candidates are
+// ranked by likelihood, then by distance.
+//
+// Because Shuffle scatters neighbouring cells across the whole grid, a wrong
+// word almost always lands far away: a small radius rejects nearly all wrong
+// readings. At most one unknown word is tolerated. Only public metropolitan
+// 1m addresses are resolved: the error is ReasonNamespace for a private or
+// overseas address, and ReasonInvalidFormat when address is not of the
+// form w1.w2.w3, optionally followed by "@1m".
+func Resolve(address string, near Coordinate, radius float64) ([]Candidate, error) {
+	head, parts, res := splitAddress(normalizeAddress(address))
+	if head != "" {
+		return nil, &AddressError{Address: address, Reason: ReasonNamespace}
+	}
+	if len(parts) != 3 || res != "" && res != ResolutionMarker+Res1m.String() {
+		return nil, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+	}
+
+	var words [3]int
+	unknown := 0
+	for i, p := range parts {
+		idx, ok := IndexOf(p)
+		if !ok {
+			idx = -1
+			unknown++
+		}
+		words[i] = idx
+	}
+
+	r := resolver{
+		seen: make(map[[3]int]int),
+	}
+	r.nearE, r.nearN = ToLambert93(near.Lat, near.Lon)
+	r.radius = radius
+
+	if unknown == 0 {
+		r.add(words, CorrectionNone, 0, 0)
+		for _, p := range [][3]int{{0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+			r.add([3]int{words[p[0]], words[p[1]], words[p[2]]}, CorrectionSwap, 0, costSwap)
+		}
+	}
+	for i, p := range parts {
+		if unknown > 1 || (unknown == 1 && words[i] >= 0) {
+			continue // a single edit cannot fix two words
+		}
+		alt := words
+		for _, h := range homophones(p) {
+			alt[i], _ = IndexOf(h)
+			r.add(alt, CorrectionHomophone, i+1, costHomophone)
+		}
+		for _, s := range SuggestWord(p, 0) {
+			if s.Distance == 0 {
+				continue
+			}
+			alt[i], _ = IndexOf(s.Word)
+			r.add(alt, CorrectionSpelling, i+1, s.Distance)
+		}
+	}
+
+	slices.SortFunc(r.out, func(a, b Candidate) int {
+		if c := cmp.Compare(a.Cost, b.Cost); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	return r.out, nil
+}
+
+// resolver accumulates the Resolve candidates falling within the radius.
+type resolver struct {
+	nearE, nearN float64
+	radius       float64
+	seen         map[[3]int]int // triplet -> position in out
+	out          []Candidate
+}
+
+func (r *resolver) add(words [3]int, corr Correction, pos int, cost float64) {
+	if i, ok := r.seen[words]; ok {
+		if cost < r.out[i].Cost {
+			r.out[i].Correction, r.out[i].Position, r.out[i].Cost = corr, pos, cost
+		}
+		return
+	}
+
+	shuffled := uint64(words[0])*w*w + uint64(words[1])*w + uint64(words[2])
+	if shuffled >= TotalCells {
+		return
+	}
+	e, n := CellCenter(Unshuffle(shuffled))
+	d := math.Hypot(e-r.nearE, n-r.nearN)
+	if d > r.radius {
+		return
+	}
+
+	lat, lon := FromLambert93(e, n)
+	r.seen[words] = len(r.out)
+	r.out = append(r.out, Candidate{
+		Address:    Address{W1: WordAt(words[0]), W2: WordAt(words[1]), W3: WordAt(words[2])},
+		Coordinate: Coordinate{Lat: lat, Lon: lon},
+		Distance:   d,
+		Correction: corr,
+		Position:   pos,
+		Cost:       cost,
+	})
+}
+
+// homophones returns the dictionary words sounding like word, word
+// excluded.
+func homophones(word string) []string {
+	var out []string
+	for _, h := range PhoneticLookup(word) {
+		if h != word {
+			out = append(out, h)
+		}
+	}
+	return out
+}
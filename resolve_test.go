@@ -0,0 +1,140 @@
+package q3m
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var eiffel = Coordinate{Lat: 48.8584, Lon: 2.2945}
+
+func TestResolveExactAddress(t *testing.T) {
+	addr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	got, err := Resolve(addr.String(), eiffel, 100)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) == 0 || got[0].Address != addr || got[0].Correction != CorrectionNone {
+		t.Fatalf("Resolve(%s) = %+v, want the address itself first", addr, got)
+	}
+	if got[0].Distance > 1 {
+		t.Errorf("distance = %f, want < 1m", got[0].Distance)
+	}
+}
+
+func TestResolveSwappedWords(t *testing.T) {
+	addr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	swapped := addr.W2 + "." + addr.W1 + "." + addr.W3
+	got, err := Resolve(swapped, eiffel, 100)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	i := slices.IndexFunc(got, func(c Candidate) bool { return c.Address == addr })
+	if i < 0 {
+		t.Fatalf("Resolve(%s) = %+v, want %s among candidates", swapped, got, addr)
+	}
+	if got[i].Correction != CorrectionSwap {
+		t.Errorf("correction = %q, want swap", got[i].Correction)
+	}
+}
+
+func TestResolveMisspelledWord(t *testing.T) {
+	addr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	typo := []byte(addr.W3)
+	typo[len(typo)-1] = 'q'
+	got, err := Resolve(addr.W1+"."+addr.W2+"."+string(typo), eiffel, 100)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Resolve = %+v, want exactly the original address", got)
+	}
+	if got[0].Address != addr || got[0].Correction != CorrectionSpelling || got[0].Position != 3 {
+		t.Errorf("candidate = %+v, want spelling fix of word 3 -> %s", got[0], addr)
+	}
+}
+
+func TestResolveHomophone(t *testing.T) {
+	// Find a point near the Eiffel Tower whose first word has a sound-alike.
+	for dy := 0.0; dy < 0.01; dy += 0.00001 {
+		addr, _ := Encode(eiffel.Lat+dy, eiffel.Lon)
+		h := homophones(addr.W1)
+		if len(h) == 0 {
+			continue
+		}
+		misheard := h[0] + "." + addr.W2 + "." + addr.W3
+		got, err := Resolve(misheard, Coordinate{eiffel.Lat + dy, eiffel.Lon}, 50)
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		i := slices.IndexFunc(got, func(c Candidate) bool { return c.Address == addr })
+		if i < 0 {
+			t.Fatalf("Resolve(%s) = %+v, want %s", misheard, got, addr)
+		}
+		if got[i].Correction != CorrectionHomophone || got[i].Position != 1 {
+			t.Errorf("candidate = %+v, want homophone fix of word 1", got[i])
+		}
+		return
+	}
+	t.Skip("no address with a homophone found near the reference point")
+}
+
+func TestResolveRadiusFilters(t *testing.T) {
+	addr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	got, err := Resolve(addr.String(), eiffel, 5000)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	for _, c := range got {
+		if c.Distance > 5000 {
+			t.Errorf("candidate %s at %fm exceeds radius", c.Address, c.Distance)
+		}
+	}
+	far, err := Resolve(addr.String(), Coordinate{Lat: 43.2951, Lon: 5.3743}, 1000)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if slices.ContainsFunc(far, func(c Candidate) bool { return c.Address == addr }) {
+		t.Error("address 660 km away should be rejected by a 1 km radius")
+	}
+}
+
+func TestResolveTwoUnknownWords(t *testing.T) {
+	got, err := Resolve("xyzzy.plugh.province", eiffel, 1e6)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Resolve with two unknown words = %v, %v; want none", got, err)
+	}
+}
+
+func TestResolveInvalidFormat(t *testing.T) {
+	for _, s := range []string{"one.two", "province.shootons@1km", "province.shootons.retirons.panade"} {
+		if _, err := Resolve(s, eiffel, 100); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Resolve(%q) error = %v, want ErrInvalidFormat", s, err)
+		}
+	}
+	if got, err := Resolve("province.shootons.retirons@1m", eiffel, 100); err != nil || len(got) == 0 {
+		t.Errorf("Resolve(@1m) = %v, %v", got, err)
+	}
+	for _, s := range []string{"re:abjurer.ramassis.rossant", "~province.shootons.retirons"} {
+		if _, err := Resolve(s, eiffel, 100); !errors.Is(err, ErrNamespace) {
+			t.Errorf("Resolve(%q) error = %v, want ErrNamespace", s, err)
+		}
+	}
+}
+
+func TestHomophones(t *testing.T) {
+	if !slices.Contains(homophones("abattais"), "abattait") {
+		t.Errorf("homophones(abattais) = %v, want abattait", homophones("abattais"))
+	}
+	if slices.Contains(homophones("abattais"), "abattais") {
+		t.Errorf("homophones(abattais) = %v, want abattais excluded", homophones("abattais"))
+	}
+}
+
+func BenchmarkResolve(b *testing.B) {
+	addr, _ := Encode(eiffel.Lat, eiffel.Lon)
+	s := addr.String()
+	for i := 0; i < b.N; i++ {
+		Resolve(s, eiffel, 100)
+	}
+}
@@ -1,72 +1,144 @@
 package q3m
 
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+)
+
 // feistelKey is the fixed shuffle key (golden ratio * 2^64). IMMUTABLE.
 const feistelKey uint64 = 0x9E3779B97F4A7C15
 
 const feistelRounds = 8
 
-// feistelHalfBits is the number of bits for each half.
-// TotalCells ~ 1.23e12 fits in 41 bits. We use 21+21=42 bits, with cycle walking.
-const feistelHalfBits = 21
+// Permutation is a bijection of [0, Domain()) that scatters neighbouring
+// cells across the address space. Shuffle maps a cell index to the number
+// written in words; Unshuffle inverts it.
+type Permutation interface {
+	Domain() uint64
+	Shuffle(idx uint64) uint64
+	Unshuffle(idx uint64) uint64
+}
+
+// feistel is a keyed permutation of [0, domain): a balanced Feistel network
+// over the smallest even bit width covering the domain, with cycle walking.
+type feistel struct {
+	keys     [feistelRounds]uint64
+	halfBits uint
+	halfMask uint64
+	domain   uint64
+}
+
+// newFeistel returns the permutation of [0, domain) keyed by key, round r
+// using (r+1)*key. TotalCells ~ 1.23e12 fits in 41 bits: the default grid
+// uses 21+21=42 bits.
+func newFeistel(key, domain uint64) feistel {
+	var keys [feistelRounds]uint64
+	for r := range keys {
+		keys[r] = uint64(r+1) * key
+	}
+	return newFeistelRounds(keys, domain)
+}
+
+// newSecretFeistel returns the permutation of [0, domain) whose round keys
+// are derived from secret with HMAC-SHA256, one independent key per round.
+func newSecretFeistel(secret []byte, domain uint64) feistel {
+	var keys [feistelRounds]uint64
+	for r := range keys {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte("q3m/feistel/round/" + strconv.Itoa(r)))
+		keys[r] = binary.BigEndian.Uint64(mac.Sum(nil))
+	}
+	return newFeistelRounds(keys, domain)
+}
 
-const feistelHalfMask = (uint64(1) << feistelHalfBits) - 1 // 0x1FFFFF
+func newFeistelRounds(keys [feistelRounds]uint64, domain uint64) feistel {
+	half := max(uint(bits.Len64(domain-1)+1)/2, 1)
+	return feistel{
+		keys:     keys,
+		halfBits: half,
+		halfMask: (uint64(1) << half) - 1,
+		domain:   domain,
+	}
+}
+
+// defaultFeistel is the permutation behind Shuffle. IMMUTABLE.
+var defaultFeistel = newFeistel(feistelKey, TotalCells)
 
-// roundFunc is the Feistel round function. It mixes the half-block with a
-// round-dependent key using SplitMix64-style mixing.
-func roundFunc(val uint64, round uint64) uint64 {
-	x := val + round*feistelKey + feistelKey
+// round is the Feistel round function. It mixes the half-block with the
+// round key using SplitMix64-style mixing.
+func (f feistel) round(val uint64, round uint64) uint64 {
+	x := val + f.keys[round]
 	x ^= x >> 30
 	x *= 0xBF58476D1CE4E5B9
 	x ^= x >> 27
 	x *= 0x94D049BB133111EB
 	x ^= x >> 31
-	return x & feistelHalfMask
+	return x & f.halfMask
 }
 
-// feistelEncrypt applies a balanced Feistel network permutation.
-func feistelEncrypt(val uint64) uint64 {
-	left := (val >> feistelHalfBits) & feistelHalfMask
-	right := val & feistelHalfMask
+// encrypt applies the balanced Feistel network.
+func (f feistel) encrypt(val uint64) uint64 {
+	left := (val >> f.halfBits) & f.halfMask
+	right := val & f.halfMask
 
 	for round := uint64(0); round < feistelRounds; round++ {
-		newRight := left ^ roundFunc(right, round)
+		newRight := left ^ f.round(right, round)
 		left = right
 		right = newRight
 	}
 
-	return (left << feistelHalfBits) | right
+	return (left << f.halfBits) | right
 }
 
-// feistelDecrypt inverts feistelEncrypt.
-func feistelDecrypt(val uint64) uint64 {
-	left := (val >> feistelHalfBits) & feistelHalfMask
-	right := val & feistelHalfMask
+// decrypt inverts encrypt.
+func (f feistel) decrypt(val uint64) uint64 {
+	left := (val >> f.halfBits) & f.halfMask
+	right := val & f.halfMask
 
 	for r := feistelRounds - 1; r >= 0; r-- {
 		round := uint64(r)
-		newLeft := right ^ roundFunc(left, round)
+		newLeft := right ^ f.round(left, round)
 		right = left
 		left = newLeft
 	}
 
-	return (left << feistelHalfBits) | right
+	return (left << f.halfBits) | right
+}
+
+// Domain returns the size of the permuted range.
+func (f feistel) Domain() uint64 {
+	return f.domain
+}
+
+// Shuffle maps idx in [0, domain) to [0, domain), cycle walking until the
+// network lands inside the domain.
+func (f feistel) Shuffle(idx uint64) uint64 {
+	result := f.encrypt(idx)
+	for result >= f.domain {
+		result = f.encrypt(result)
+	}
+	return result
+}
+
+// Unshuffle inverts Shuffle.
+func (f feistel) Unshuffle(idx uint64) uint64 {
+	result := f.decrypt(idx)
+	for result >= f.domain {
+		result = f.decrypt(result)
+	}
+	return result
 }
 
 // Shuffle applies a bijective permutation on idx within [0, TotalCells).
 // Uses cycle walking to handle the non-power-of-2 domain.
 func Shuffle(idx uint64) uint64 {
-	result := feistelEncrypt(idx)
-	for result >= TotalCells {
-		result = feistelEncrypt(result)
-	}
-	return result
+	return defaultFeistel.Shuffle(idx)
 }
 
 // Unshuffle inverts Shuffle: given a shuffled index, returns the original.
 func Unshuffle(idx uint64) uint64 {
-	result := feistelDecrypt(idx)
-	for result >= TotalCells {
-		result = feistelDecrypt(result)
-	}
-	return result
+	return defaultFeistel.Unshuffle(idx)
 }
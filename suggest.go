@@ -0,0 +1,180 @@
+package q3m
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// suggestMaxEdits bounds the Levenshtein radius searched for suggestions.
+const suggestMaxEdits = 2
+
+// Edit costs used to rank suggestions. Hitting a neighbouring key on an
+// AZERTY keyboard is a cheaper mistake than an arbitrary substitution.
+const (
+	costEdit      = 1.0
+	costAdjacent  = 0.5
+	costTranspose = 1.0
+)
+
+// azertyRows is the letter block of a French AZERTY keyboard.
+var azertyRows = [...]string{
+	"azertyuiop",
+	"qsdfghjklm",
+	"wxcvbn",
+}
+
+// azertyAdjacent[a][b] reports whether keys a and b touch on the keyboard.
+var azertyAdjacent = func() (adj [26][26]bool) {
+	type pos struct{ row, col int }
+	keys := make(map[byte]pos)
+	for r, row := range azertyRows {
+		for c := 0; c < len(row); c++ {
+			keys[row[c]] = pos{r, c}
+		}
+	}
+	// Staggered rows: key (r, c) touches (r-1, c), (r-1, c+1), (r+1, c-1)
+	// and (r+1, c), plus its left and right neighbours.
+	touches := func(a, b pos) bool {
+		switch b.row - a.row {
+		case 0:
+			return b.col == a.col-1 || b.col == a.col+1
+		case -1:
+			return b.col == a.col || b.col == a.col+1
+		case 1:
+			return b.col == a.col-1 || b.col == a.col
+		}
+		return false
+	}
+	for a, pa := range keys {
+		for b, pb := range keys {
+			if touches(pa, pb) {
+				adj[a-'a'][b-'a'] = true
+			}
+		}
+	}
+	return adj
+}()
+
+// substCost returns the cost of typing b instead of a.
+func substCost(a, b byte) float64 {
+	if a == b {
+		return 0
+	}
+	if a >= 'a' && a <= 'z' && b >= 'a' && b <= 'z' && azertyAdjacent[a-'a'][b-'a'] {
+		return costAdjacent
+	}
+	return costEdit
+}
+
+// keyboardDistance is an optimal-string-alignment distance where
+// substitutions between adjacent AZERTY keys cost less than other edits.
+func keyboardDistance(a, b string) float64 {
+	d := make([][]float64, len(a)+1)
+	for i := range d {
+		d[i] = make([]float64, len(b)+1)
+		d[i][0] = float64(i) * costEdit
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j] = float64(j) * costEdit
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			d[i][j] = min(
+				d[i-1][j]+costEdit,
+				d[i][j-1]+costEdit,
+				d[i-1][j-1]+substCost(a[i-1], b[j-1]),
+			)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+costTranspose)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+var (
+	bkOnce sync.Once
+	bkDict *bkTree
+)
+
+// dictTree returns the BK-tree over the embedded dictionary.
+func dictTree() *bkTree {
+	bkOnce.Do(func() {
+		loadWords()
+		bkDict = newBKTree(wordsList)
+	})
+	return bkDict
+}
+
+// Suggestion is a dictionary word proposed in place of a mistyped word.
+type Suggestion struct {
+	Word     string  `json:"word"`
+	Distance float64 `json:"distance"` // keyboard-weighted edit distance
+}
+
+// WordSuggestions lists the candidates for one unknown word of an address.
+type WordSuggestions struct {
+	Position   int          `json:"position"` // 1-based word position
+	Token      string       `json:"token"`
+	Candidates []Suggestion `json:"candidates"`
+}
+
+// SuggestWord returns up to max dictionary words close to word, best first.
+// Candidates are found within two edits and ranked by their AZERTY-weighted
+// edit distance. A max of zero or less returns every candidate.
+func SuggestWord(word string, max int) []Suggestion {
+	word = strings.ToLower(strings.TrimSpace(word))
+	out := []Suggestion{}
+	dictTree().search(word, suggestMaxEdits, func(cand string, _ int) {
+		out = append(out, Suggestion{Word: cand, Distance: keyboardDistance(word, cand)})
+	})
+	slices.SortFunc(out, func(a, b Suggestion) int {
+		if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Word, b.Word)
+	})
+	if max > 0 && len(out) > max {
+		out = out[:max]
+	}
+	return out
+}
+
+// Suggest proposes corrections for every unknown word of address, in word
+// order, numbered after the private marker and region prefix if any. Words
+// found in the dictionary are skipped, so a valid address yields an empty
+// result. The error is non-nil only when address names an unknown region or
+// does not have the number of words of its resolution: three, or four with
+// the word of a sub-cell, at 1m, and two at 1km.
+func Suggest(address string, max int) ([]WordSuggestions, error) {
+	c, err := defaultCodec().codecFor(address)
+	if err != nil {
+		return nil, err
+	}
+	_, parts, marker := splitAddress(normalizeAddress(address))
+	r := Res1m
+	if marker != "" {
+		if r, err = ParseResolution(strings.TrimPrefix(marker, ResolutionMarker)); err != nil {
+			return nil, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+		}
+	}
+	n := c.Words(r)
+	if len(parts) != n && (r != Res1m || len(parts) != n+1) {
+		return nil, &AddressError{Address: address, Reason: ReasonInvalidFormat}
+	}
+
+	var out []WordSuggestions
+	for i, p := range parts {
+		if _, ok := IndexOf(p); ok {
+			continue
+		}
+		out = append(out, WordSuggestions{
+			Position:   i + 1,
+			Token:      p,
+			Candidates: SuggestWord(p, max),
+		})
+	}
+	return out, nil
+}
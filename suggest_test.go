@@ -0,0 +1,140 @@
+package q3m
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAZERTYAdjacency(t *testing.T) {
+	cases := []struct {
+		a, b byte
+		want bool
+	}{
+		{'a', 'z', true},
+		{'a', 'q', true},
+		{'q', 'w', true},
+		{'s', 'w', true},
+		{'m', 'p', true},
+		{'x', 'c', true},
+		{'a', 'p', false},
+		{'q', 'z', true},
+		{'q', 'e', false},
+		{'n', 'm', false},
+	}
+	for _, c := range cases {
+		if got := azertyAdjacent[c.a-'a'][c.b-'a']; got != c.want {
+			t.Errorf("adjacent(%c, %c) = %v, want %v", c.a, c.b, got, c.want)
+		}
+		if got := azertyAdjacent[c.b-'a'][c.a-'a']; got != c.want {
+			t.Errorf("adjacent(%c, %c) = %v, want %v (symmetry)", c.b, c.a, got, c.want)
+		}
+	}
+}
+
+func TestKeyboardDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"province", "province", 0},
+		{"provinxe", "province", costAdjacent},
+		{"provinpe", "province", costEdit},
+		{"provicne", "province", costTranspose},
+		{"provinc", "province", costEdit},
+	}
+	for _, c := range cases {
+		if got := keyboardDistance(c.a, c.b); got != c.want {
+			t.Errorf("keyboardDistance(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestSuggestWordRanksKeyboardTypoFirst(t *testing.T) {
+	got := SuggestWord("provinxe", 5)
+	if len(got) == 0 || got[0].Word != "province" {
+		t.Fatalf("SuggestWord(provinxe) = %v, want province first", got)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Distance < got[i-1].Distance {
+			t.Errorf("suggestions not sorted: %v", got)
+		}
+	}
+	if len(got) > 5 {
+		t.Errorf("SuggestWord returned %d candidates, want <= 5", len(got))
+	}
+}
+
+func TestSuggestWordNoCandidate(t *testing.T) {
+	if got := SuggestWord("xqxqxqxqxq", 5); len(got) != 0 {
+		t.Errorf("SuggestWord(xqxqxqxqxq) = %v, want none", got)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	addr, err := Encode(48.8584, 2.2945)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	typo := []byte(addr.W2)
+	typo[0], typo[1] = typo[1], typo[0]
+
+	got, err := Suggest(addr.W1+"."+string(typo)+"."+addr.W3, 3)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+	if len(got) != 1 || got[0].Position != 2 || got[0].Token != string(typo) {
+		t.Fatalf("Suggest = %+v, want one entry at position 2", got)
+	}
+	found := false
+	for _, c := range got[0].Candidates {
+		if c.Word == addr.W2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("candidates %v do not include %q", got[0].Candidates, addr.W2)
+	}
+}
+
+func TestSuggestValidAddress(t *testing.T) {
+	addr, _ := Encode(48.8584, 2.2945)
+	got, err := Suggest(addr.String(), 3)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Suggest(valid) = %v, %v; want empty", got, err)
+	}
+}
+
+func TestSuggestResolutions(t *testing.T) {
+	precise, _ := EncodePrecise(48.8584, 2.2945, Precision1cm)
+	km, _ := EncodeAt(48.8584, 2.2945, Res1km)
+	for _, tt := range []struct {
+		address  string
+		position int
+	}{
+		{precise.W1 + "." + precise.W2 + "." + precise.W3 + ".provinxe", 4},
+		{"provinxe." + km.W2 + "@1km", 1},
+		{"re:abjurer.ramassis.provinxe", 3},
+		{"~provinxe.shootons.retirons", 1},
+	} {
+		got, err := Suggest(tt.address, 3)
+		if err != nil || len(got) != 1 || got[0].Position != tt.position || got[0].Token != "provinxe" {
+			t.Errorf("Suggest(%q) = %+v, %v, want provinxe at position %d", tt.address, got, err, tt.position)
+		}
+	}
+}
+
+func TestSuggestInvalidFormat(t *testing.T) {
+	for _, s := range []string{"one.two", "a.b.c.d.e", "a.b.c@1km", "a.b.c.d@10m", "a.b.c@2m", "zz:a.b.c"} {
+		if _, err := Suggest(s, 3); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Suggest(%q) error = %v, want ErrInvalidFormat", s, err)
+		}
+	}
+}
+
+func BenchmarkSuggestWord(b *testing.B) {
+	dictTree()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SuggestWord("provinxe", 5)
+	}
+}
@@ -3,9 +3,18 @@
 // Usage:
 //
 //	go run ./tools/wordgen > words_fr.txt
+//	go run ./tools/wordgen -input Lexique383.tsv > words_fr.txt
+//	go run ./tools/wordgen -input Lexique383.tsv -verify
 //
-// It downloads Lexique383, filters and curates 10800 words suitable for
-// encoding geographic coordinates, then writes one word per line to stdout.
+// It downloads Lexique383, or reads it from the -input file, filters and
+// curates 10800 words suitable for encoding geographic coordinates, then
+// writes one word per line to stdout. The selection only depends on the
+// contents of the file: words of equal frequency are ranked alphabetically.
+//
+// With -verify, it writes nothing but the differences between the
+// generated list and the dictionary embedded in q3m, one word per line
+// prefixed with "-" (embedded only) or "+" (generated only), and exits
+// with status 1 if there are any.
 //
 // Criteria:
 //   - 4-8 letters, ASCII only (no accents)
@@ -19,6 +28,7 @@ package main
 import (
 	"bufio"
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +36,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/ikarius/q3m"
 )
 
 const (
@@ -62,28 +74,68 @@ type entry struct {
 }
 
 func main() {
-	fmt.Fprintf(os.Stderr, "Downloading Lexique383...\n")
-	resp, err := http.Get(lexiqueURL)
+	input := flag.String("input", "", "read Lexique383 from this TSV file instead of downloading it")
+	verify := flag.Bool("verify", false, "compare the generated list with the embedded words_fr.txt")
+	flag.Parse()
+
+	var src io.Reader
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening input: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		src = f
+	} else {
+		fmt.Fprintf(os.Stderr, "Downloading Lexique383...\n")
+		resp, err := http.Get(lexiqueURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error downloading: %v\n", err)
+			os.Exit(1)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 200 {
+			fmt.Fprintf(os.Stderr, "HTTP %d\n", resp.StatusCode)
+			os.Exit(1)
+		}
+		src = resp.Body
+	}
+
+	words, err := generate(src)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error downloading: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		fmt.Fprintf(os.Stderr, "HTTP %d\n", resp.StatusCode)
-		os.Exit(1)
+	if *verify {
+		if n := diff(os.Stdout, embeddedWords(), words); n > 0 {
+			fmt.Fprintf(os.Stderr, "%d differences with the embedded dictionary\n", n)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Generated list matches the embedded dictionary (%d words)\n", len(words))
+		return
 	}
 
+	for _, w := range words {
+		fmt.Println(w)
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %d words\n", len(words))
+}
+
+// generate selects the dictionary from the Lexique383 TSV read from r, in
+// alphabetical order.
+func generate(r io.Reader) ([]string, error) {
 	// Lexique383 is a TSV file.
-	reader := csv.NewReader(bufio.NewReader(resp.Body))
+	reader := csv.NewReader(bufio.NewReader(r))
 	reader.Comma = '\t'
 	reader.LazyQuotes = true
 
 	header, err := reader.Read()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading header: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("reading header: %v", err)
 	}
 
 	// Find column indices.
@@ -95,9 +147,7 @@ func main() {
 	needed := []string{"ortho", "lemme", "cgram", "freqlemfilms2", "nbhomoph", "islem"}
 	for _, n := range needed {
 		if _, ok := colIdx[n]; !ok {
-			fmt.Fprintf(os.Stderr, "Missing column: %s\n", n)
-			fmt.Fprintf(os.Stderr, "Available: %v\n", header)
-			os.Exit(1)
+			return nil, fmt.Errorf("missing column %s (available: %v)", n, header)
 		}
 	}
 
@@ -175,9 +225,14 @@ func main() {
 		fmt.Fprintf(os.Stderr, "WARNING: only %d candidates, need %d\n", len(candidates), targetSize)
 	}
 
-	// Sort by frequency (descending) and pick top N.
+	// Sort by frequency (descending) and pick top N. Equal frequencies
+	// are frequent in Lexique383: rank them alphabetically so that the
+	// cut at targetSize does not depend on the sort algorithm.
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].freq > candidates[j].freq
+		if candidates[i].freq != candidates[j].freq {
+			return candidates[i].freq > candidates[j].freq
+		}
+		return candidates[i].word < candidates[j].word
 	})
 
 	if len(candidates) > targetSize {
@@ -189,9 +244,50 @@ func main() {
 		return candidates[i].word < candidates[j].word
 	})
 
-	for _, c := range candidates {
-		fmt.Println(c.word)
+	words := make([]string, len(candidates))
+	for i, c := range candidates {
+		words[i] = c.word
 	}
+	return words, nil
+}
 
-	fmt.Fprintf(os.Stderr, "Generated %d words\n", len(candidates))
+// embeddedWords returns the dictionary embedded in q3m, in its order.
+func embeddedWords() []string {
+	dict := q3m.DefaultDictionary()
+	words := make([]string, dict.Len())
+	for i := range words {
+		words[i] = dict.Word(i)
+	}
+	return words
+}
+
+// diff writes to w the words only in want, prefixed with "-", and only in
+// got, prefixed with "+", both lists being sorted, and returns their
+// number. Lists holding the same words in another order differ as a whole.
+func diff(w io.Writer, want, got []string) int {
+	if !sort.StringsAreSorted(want) || !sort.StringsAreSorted(got) {
+		if strings.Join(want, "\n") == strings.Join(got, "\n") {
+			return 0
+		}
+		fmt.Fprintln(w, "word lists are not in the same order")
+		return 1
+	}
+	n := 0
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case j == len(got) || i < len(want) && want[i] < got[j]:
+			fmt.Fprintf(w, "-%s\n", want[i])
+			i++
+			n++
+		case i == len(want) || got[j] < want[i]:
+			fmt.Fprintf(w, "+%s\n", got[j])
+			j++
+			n++
+		default:
+			i++
+			j++
+		}
+	}
+	return n
 }
@@ -0,0 +1,113 @@
+package q3m
+
+import "math"
+
+// GRS80 semi-major axis and flattening, shared by the RGAF09, RGFG95, RGR92
+// and RGM04 frames of the overseas departments.
+const (
+	grs80A = 6378137.0
+	grs80F = 1 / 298.257222101
+)
+
+// UTM projection constants.
+const (
+	utmK0     = 0.9996
+	utmE0     = 500000.0
+	utmNSouth = 10000000.0 // false northing of the southern hemisphere
+)
+
+// Krüger series coefficients to the third order in the third flattening n,
+// accurate to about a millimetre within a zone.
+var (
+	utmN = grs80F / (2 - grs80F)
+	utmA = grs80A / (1 + utmN) * (1 + utmN*utmN/4 + utmN*utmN*utmN*utmN/64)
+
+	utmAlpha = [3]float64{
+		utmN/2 - 2*utmN*utmN/3 + 5*utmN*utmN*utmN/16,
+		13*utmN*utmN/48 - 3*utmN*utmN*utmN/5,
+		61 * utmN * utmN * utmN / 240,
+	}
+	utmBeta = [3]float64{
+		utmN/2 - 2*utmN*utmN/3 + 37*utmN*utmN*utmN/96,
+		utmN*utmN/48 + utmN*utmN*utmN/15,
+		17 * utmN * utmN * utmN / 480,
+	}
+	utmDelta = [3]float64{
+		2*utmN - 2*utmN*utmN/3 - 2*utmN*utmN*utmN,
+		7*utmN*utmN/3 - 8*utmN*utmN*utmN/5,
+		56 * utmN * utmN * utmN / 15,
+	}
+)
+
+// utmLambda0 returns the central meridian of a UTM zone, in radians.
+func utmLambda0(zone int) float64 {
+	return float64(6*zone-183) * math.Pi / 180
+}
+
+// ToUTM converts WGS84 (lat, lon in degrees) to UTM (E, N in metres) in the
+// given zone, with the false northing of the southern hemisphere if south.
+func ToUTM(lat, lon float64, zone int, south bool) (E, N float64) {
+	phi := lat * math.Pi / 180
+	dLambda := lon*math.Pi/180 - utmLambda0(zone)
+
+	sinPhi := math.Sin(phi)
+	t := math.Sinh(math.Atanh(sinPhi) - grs80E*math.Atanh(grs80E*sinPhi))
+	xi := math.Atan2(t, math.Cos(dLambda))
+	eta := math.Atanh(math.Sin(dLambda) / math.Sqrt(1+t*t))
+
+	x, y := eta, xi
+	for j, a := range utmAlpha {
+		k := 2 * float64(j+1)
+		x += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		y += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	E = utmE0 + utmK0*utmA*x
+	N = utmK0 * utmA * y
+	if south {
+		N += utmNSouth
+	}
+	return
+}
+
+// FromUTM converts UTM (E, N in metres) in the given zone to WGS84 (lat, lon
+// in degrees).
+func FromUTM(E, N float64, zone int, south bool) (lat, lon float64) {
+	if south {
+		N -= utmNSouth
+	}
+	xi := N / (utmK0 * utmA)
+	eta := (E - utmE0) / (utmK0 * utmA)
+
+	xi1, eta1 := xi, eta
+	for j, b := range utmBeta {
+		k := 2 * float64(j+1)
+		xi1 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta1 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xi1) / math.Cosh(eta1))
+
+	phi := chi
+	for j, d := range utmDelta {
+		phi += d * math.Sin(2*float64(j+1)*chi)
+	}
+	lambda := utmLambda0(zone) + math.Atan2(math.Sinh(eta1), math.Cos(xi1))
+	return phi * 180 / math.Pi, lambda * 180 / math.Pi
+}
+
+// utmZone is the projection of the grid of an overseas region.
+type utmZone struct {
+	zone  int
+	south bool
+}
+
+func (u utmZone) forward(lat, lon float64) (float64, float64) {
+	return ToUTM(lat, lon, u.zone, u.south)
+}
+
+func (u utmZone) inverse(E, N float64) (float64, float64) {
+	return FromUTM(E, N, u.zone, u.south)
+}
+
+func (u utmZone) scale(lat, lon float64) float64 {
+	return utmScale(lat, lon, u.zone, u.south)
+}
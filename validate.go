@@ -0,0 +1,93 @@
+package q3m
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidationResult is the diagnosis of an address by Validate.
+type ValidationResult struct {
+	Address string      `json:"address"` // the address as given
+	Valid   bool        `json:"valid"`   // whether Decode accepts it
+	Words   []WordCheck `json:"words"`   // each word of the normalized address
+
+	// InvalidCell reports known words whose triplet lies in the range left
+	// unused by the permutation, above the cell count of the grid.
+	InvalidCell bool `json:"invalid_cell"`
+	// InCoverage reports a valid address whose cell centre lies in the land
+	// coverage; overseas regions have no coverage and are always in it.
+	InCoverage bool `json:"in_coverage"`
+
+	Reason  Reason `json:"reason,omitempty"` // why Decode rejects it
+	Message string `json:"message"`          // human-readable verdict
+
+	err error
+}
+
+// WordCheck is the dictionary membership of one word of an address.
+type WordCheck struct {
+	Position int    `json:"position"` // 1-based
+	Word     string `json:"word"`
+	Known    bool   `json:"known"`
+}
+
+// Err returns the *AddressError for which Decode rejects the address, or
+// nil if it is valid.
+func (r ValidationResult) Err() error {
+	return r.err
+}
+
+// Validate checks address with DefaultCodec (see Codec.Validate).
+func Validate(address string) ValidationResult {
+	return defaultCodec().Validate(address)
+}
+
+// Validate diagnoses address without decoding it: it reports whether each
+// word is in the dictionary, whether the address is valid, whether its
+// triplet falls in the invalid range (1 - TotalCells/10800³, about 2.3% of
+// the triplets of known words with the default grid), and whether its cell
+// lies in the land coverage, tested in grid coordinates so that no
+// projection is computed.
+// An address outside the coverage is valid: Decode accepts it.
+func (c *Codec) Validate(address string) ValidationResult {
+	res := ValidationResult{Address: address}
+
+	body := normalizeAddress(address)
+	body = strings.TrimPrefix(body, PrivateMarker)
+	if _, words, ok := strings.Cut(body, RegionMarker); ok {
+		body = words
+	}
+	body, _, _ = strings.Cut(body, ResolutionMarker)
+	for i, w := range strings.Split(body, ".") {
+		_, known := c.dict.Index(w)
+		res.Words = append(res.Words, WordCheck{Position: i + 1, Word: w, Known: known})
+	}
+
+	rc, err := c.codecFor(address)
+	var cell Cell
+	if err == nil {
+		_, cell, err = rc.parse(address)
+	}
+	if err != nil {
+		res.err = err
+		res.Message = err.Error()
+		var ae *AddressError
+		if errors.As(err, &ae) {
+			res.Reason = ae.Reason
+			res.InvalidCell = ae.Reason == ReasonInvalidCell
+		}
+		return res
+	}
+
+	res.Valid = true
+	cov := rc.coverage
+	if cov == nil && rc.region == nil {
+		cov = DefaultCoverage()
+	}
+	res.InCoverage = cov == nil || cov.ContainsLambert(rc.levels[cell.res].grid.cellCenter(cell.idx))
+	res.Message = "valid address"
+	if !res.InCoverage {
+		res.Message = "valid address outside the land coverage"
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+package q3m
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	last := WordAt(DictSize - 1)
+	tests := []struct {
+		address     string
+		valid       bool
+		known       []bool
+		invalidCell bool
+		inCoverage  bool
+		reason      Reason
+	}{
+		{"province.shootons.retirons", true, []bool{true, true, true}, false, true, ""},
+		{"Province Shootons Retirons", true, []bool{true, true, true}, false, true, ""},
+		{"provinxe.shootons.xyzzy", false, []bool{false, true, false}, false, false, ReasonUnknownWord},
+		{last + "." + last + "." + last, false, []bool{true, true, true}, true, false, ReasonInvalidCell},
+		{"province.shootons", false, []bool{true, true}, false, false, ReasonInvalidFormat},
+		{"~province.shootons.retirons", false, []bool{true, true, true}, false, false, ReasonNamespace},
+		{"re:abjurer.ramassis.rossant", true, []bool{true, true, true}, false, true, ""},
+	}
+	for _, tt := range tests {
+		r := Validate(tt.address)
+		var known []bool
+		for _, w := range r.Words {
+			known = append(known, w.Known)
+		}
+		if r.Valid != tt.valid || r.InvalidCell != tt.invalidCell || r.InCoverage != tt.inCoverage || r.Reason != tt.reason || len(known) != len(tt.known) {
+			t.Errorf("Validate(%q) = %+v", tt.address, r)
+			continue
+		}
+		for i := range known {
+			if known[i] != tt.known[i] {
+				t.Errorf("Validate(%q) word %d known = %v", tt.address, i+1, known[i])
+			}
+		}
+		if _, err := Decode(tt.address); fmt.Sprint(err) != fmt.Sprint(r.Err()) {
+			t.Errorf("Validate(%q) disagrees with Decode: %v, %v", tt.address, r.Err(), err)
+		}
+	}
+
+	// A point of the grid at sea, in the Bay of Biscay.
+	sea, _ := Encode(46.0, -3.0)
+	if r := Validate(sea.String()); !r.Valid || r.InCoverage || r.Message == "" {
+		t.Errorf("Validate(at sea) = %+v", r)
+	}
+}
+
+func TestValidateInvalidRange(t *testing.T) {
+	// triplet returns the address whose words spell i in base DictSize.
+	triplet := func(i uint64) string {
+		n := uint64(DictSize)
+		return WordAt(int(i/(n*n))) + "." + WordAt(int(i/n%n)) + "." + WordAt(int(i%n))
+	}
+
+	if r := Validate(triplet(TotalCells)); r.Valid || !r.InvalidCell || r.Reason != ReasonInvalidCell {
+		t.Errorf("Validate(%s), first invalid triplet = %+v", triplet(TotalCells), r)
+	}
+	if r := Validate(triplet(TotalCells - 1)); !r.Valid || r.InvalidCell {
+		t.Errorf("Validate(%s), last valid triplet = %+v", triplet(TotalCells-1), r)
+	}
+}
@@ -3,6 +3,8 @@ package q3m
 import (
 	_ "embed"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -13,38 +15,124 @@ var wordsRaw string
 // DictSize is the number of words in the dictionary.
 const DictSize = 10800
 
+// maxDictionarySize keeps Len()^3 within a uint64.
+const maxDictionarySize = 1 << 21
+
+// Dictionary is an ordered word list: the position of a word is the digit it
+// stands for in an address.
+type Dictionary struct {
+	words []string
+	index map[string]int
+
+	// sorted holds words in alphabetical order for prefix search, built on
+	// first use.
+	sortOnce sync.Once
+	sorted   []string
+
+	// phonetic holds words by PhoneticKey, built on first use.
+	phoneticOnce sync.Once
+	phonetic     map[string][]string
+}
+
+// NewDictionary returns a dictionary of words, in the given order. Words must
+// be non-empty, unique, free of separators and markers, and left unchanged by
+// NormalizeAddress (lowercase, unaccented), so that every address decodes
+// back to its cell once typed.
+func NewDictionary(words []string) (*Dictionary, error) {
+	if len(words) == 0 || len(words) > maxDictionarySize {
+		return nil, fmt.Errorf("q3m: dictionary size %d out of [1, %d]", len(words), maxDictionarySize)
+	}
+	d := &Dictionary{
+		words: make([]string, len(words)),
+		index: make(map[string]int, len(words)),
+	}
+	for i, w := range words {
+		if w == "" || strings.ContainsFunc(w, isAddressSeparator) ||
+			strings.ContainsFunc(w, isAddressMarker) {
+			return nil, fmt.Errorf("q3m: invalid dictionary word %q", w)
+		}
+		if n, _ := NormalizeAddress(w); n != w {
+			return nil, fmt.Errorf("q3m: invalid dictionary word %q", w)
+		}
+		if _, dup := d.index[w]; dup {
+			return nil, fmt.Errorf("q3m: duplicate dictionary word %q", w)
+		}
+		d.words[i] = w
+		d.index[w] = i
+	}
+	return d, nil
+}
+
+// Len returns the number of words in d.
+func (d *Dictionary) Len() int {
+	return len(d.words)
+}
+
+// Word returns the word at position i in d.
+func (d *Dictionary) Word(i int) string {
+	return d.words[i]
+}
+
+// Index returns the position of word in d, ignoring case.
+// Returns -1 and false if the word is not found.
+func (d *Dictionary) Index(word string) (int, bool) {
+	idx, ok := d.index[strings.ToLower(word)]
+	if !ok {
+		return -1, false
+	}
+	return idx, true
+}
+
+// Complete returns up to limit words of d starting with prefix, ignoring
+// case, in alphabetical order. A limit of zero or less returns every match.
+func (d *Dictionary) Complete(prefix string, limit int) []string {
+	d.sortOnce.Do(func() {
+		d.sorted = slices.Clone(d.words)
+		slices.Sort(d.sorted)
+	})
+	prefix = strings.ToLower(prefix)
+	i := sort.SearchStrings(d.sorted, prefix)
+	j := i
+	for j < len(d.sorted) && strings.HasPrefix(d.sorted[j], prefix) && (limit <= 0 || j-i < limit) {
+		j++
+	}
+	return slices.Clone(d.sorted[i:j])
+}
+
 var (
 	wordsOnce  sync.Once
+	wordsDict  *Dictionary
 	wordsList  []string
 	wordsIndex map[string]int
 )
 
 func loadWords() {
 	wordsOnce.Do(func() {
-		wordsList = strings.Split(strings.TrimSpace(wordsRaw), "\n")
-		if len(wordsList) != DictSize {
-			panic(fmt.Sprintf("q3m: dictionary has %d words, expected %d", len(wordsList), DictSize))
+		list := strings.Split(strings.TrimSpace(wordsRaw), "\n")
+		if len(list) != DictSize {
+			panic(fmt.Sprintf("q3m: dictionary has %d words, expected %d", len(list), DictSize))
 		}
-		wordsIndex = make(map[string]int, DictSize)
-		for i, w := range wordsList {
-			wordsIndex[w] = i
+		d, err := NewDictionary(list)
+		if err != nil {
+			panic(err)
 		}
+		wordsDict, wordsList, wordsIndex = d, d.words, d.index
 	})
 }
 
+// DefaultDictionary returns the embedded French dictionary of DictSize words.
+func DefaultDictionary() *Dictionary {
+	loadWords()
+	return wordsDict
+}
+
 // WordAt returns the word at position i in the dictionary.
 func WordAt(i int) string {
-	loadWords()
-	return wordsList[i]
+	return DefaultDictionary().Word(i)
 }
 
 // IndexOf returns the index of word in the dictionary.
 // Returns -1 and false if the word is not found.
 func IndexOf(word string) (int, bool) {
-	loadWords()
-	idx, ok := wordsIndex[strings.ToLower(word)]
-	if !ok {
-		return -1, false
-	}
-	return idx, true
+	return DefaultDictionary().Index(word)
 }